@@ -2,6 +2,11 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 type Config struct {
@@ -10,6 +15,41 @@ type Config struct {
 	JWTSecret   string
 	DB          DBConfig
 	MinIO       MinIOConfig
+	Estimate    EstimateConfig
+	Order       OrderConfig
+	Payment     PaymentConfig
+	Events      EventsConfig
+}
+
+type EstimateConfig struct {
+	TTL             time.Duration
+	MaxItemQuantity int
+}
+
+// OrderConfig holds the cancellation policy. Orders are free to cancel until
+// a merchant accepts them; after that CancellationFeePercent of the total is
+// kept.
+type OrderConfig struct {
+	CancellationFeePercent int
+}
+
+// DefaultPaymentWebhookSecret is the webhook secret used when none is set. It
+// is refused in production.
+const DefaultPaymentWebhookSecret = "your-webhook-secret"
+
+// PaymentConfig selects the payment provider. WebhookSecret verifies the
+// signature of its webhooks. Orders not paid within Timeout are cancelled
+// and their stock released.
+type PaymentConfig struct {
+	Provider      string
+	WebhookSecret string
+	Timeout       time.Duration
+}
+
+// EventsConfig lists the origins besides the API's own whose pages may open
+// order event WebSockets.
+type EventsConfig struct {
+	AllowedOrigins []string
 }
 
 type MinIOConfig struct {
@@ -38,6 +78,22 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt parses an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDuration parses a duration environment variable or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
 func LoadConfig() *Config {
 	cfg := &Config{
 		Environment: getEnv("ENVIRONMENT", "development"),
@@ -45,6 +101,10 @@ func LoadConfig() *Config {
 		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
 		DB:          *LoadDBConfig(),
 		MinIO:       *LoadMinIOConfig(),
+		Estimate:    *LoadEstimateConfig(),
+		Order:       *LoadOrderConfig(),
+		Payment:     *LoadPaymentConfig(),
+		Events:      *LoadEventsConfig(),
 	}
 	return cfg
 }
@@ -70,3 +130,38 @@ func LoadMinIOConfig() *MinIOConfig {
 		BucketName:      getEnv("MINIO_BUCKET_NAME", "belimang-files"),
 	}
 }
+
+func LoadEstimateConfig() *EstimateConfig {
+	return &EstimateConfig{
+		TTL:             getEnvDuration("ESTIMATE_TTL", 15*time.Minute),
+		MaxItemQuantity: getEnvInt("ESTIMATE_MAX_ITEM_QUANTITY", 100),
+	}
+}
+
+func LoadOrderConfig() *OrderConfig {
+	feePercent := getEnvInt("ORDER_CANCELLATION_FEE_PERCENT", 20)
+	if feePercent < 0 || feePercent > 100 {
+		log.Fatal().Msgf("ORDER_CANCELLATION_FEE_PERCENT must be between 0 and 100, got %d", feePercent)
+	}
+	return &OrderConfig{
+		CancellationFeePercent: feePercent,
+	}
+}
+
+func LoadPaymentConfig() *PaymentConfig {
+	return &PaymentConfig{
+		Provider:      getEnv("PAYMENT_PROVIDER", "fake"),
+		WebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", DefaultPaymentWebhookSecret),
+		Timeout:       getEnvDuration("PAYMENT_TIMEOUT", 30*time.Minute),
+	}
+}
+
+func LoadEventsConfig() *EventsConfig {
+	var origins []string
+	for _, origin := range strings.Split(getEnv("EVENTS_ALLOWED_ORIGINS", ""), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, strings.ToLower(strings.TrimSuffix(origin, "/")))
+		}
+	}
+	return &EventsConfig{AllowedOrigins: origins}
+}
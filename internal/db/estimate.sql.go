@@ -7,46 +7,149 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const getMerchantItemPriceByID = `-- name: GetMerchantItemPriceByID :one
-SELECT 
-  id::text AS id,
-  price::int4 AS price
+const getEstimateItems = `-- name: GetEstimateItems :many
+SELECT
+  id,
+  merchant_id,
+  price,
+  available,
+  stock
 FROM merchant_items
-WHERE id = ($1)::text::uuid
+WHERE deleted_at IS NULL AND id = ANY($1::uuid[])
 `
 
-type GetMerchantItemPriceByIDRow struct {
-	ID    string
-	Price int32
+type GetEstimateItemsRow struct {
+	ID         pgtype.UUID
+	MerchantID pgtype.UUID
+	Price      int32
+	Available  bool
+	Stock      pgtype.Int4
 }
 
-func (q *Queries) GetMerchantItemPriceByID(ctx context.Context, dollar_1 string) (GetMerchantItemPriceByIDRow, error) {
-	row := q.db.QueryRow(ctx, getMerchantItemPriceByID, dollar_1)
-	var i GetMerchantItemPriceByIDRow
-	err := row.Scan(&i.ID, &i.Price)
-	return i, err
+func (q *Queries) GetEstimateItems(ctx context.Context, ids []pgtype.UUID) ([]GetEstimateItemsRow, error) {
+	rows, err := q.db.Query(ctx, getEstimateItems, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEstimateItemsRow
+	for rows.Next() {
+		var i GetEstimateItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchantID,
+			&i.Price,
+			&i.Available,
+			&i.Stock,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getMerchantLocationByID = `-- name: GetMerchantLocationByID :one
-SELECT 
-  id::text AS id,
+const getEstimateMerchants = `-- name: GetEstimateMerchants :many
+SELECT
+  id,
+  merchant_category,
   ST_Y(location::geometry)::float8 AS lat,
-  ST_X(location::geometry)::float8 AS long
+  ST_X(location::geometry)::float8 AS long,
+  time_zone,
+  merchant_can_deliver(
+    location,
+    delivery_radius_m,
+    ST_SetSRID(ST_MakePoint($1::float8, $2::float8), 4326)
+  )::bool AS deliverable
 FROM merchants
-WHERE id = ($1)::text::uuid
+WHERE deleted_at IS NULL AND id = ANY($3::uuid[])
 `
 
-type GetMerchantLocationByIDRow struct {
-	ID   string
-	Lat  float64
-	Long float64
+type GetEstimateMerchantsParams struct {
+	UserLong float64
+	UserLat  float64
+	Ids      []pgtype.UUID
+}
+
+type GetEstimateMerchantsRow struct {
+	ID               pgtype.UUID
+	MerchantCategory MerchantCategory
+	Lat              float64
+	Long             float64
+	TimeZone         string
+	Deliverable      bool
 }
 
-func (q *Queries) GetMerchantLocationByID(ctx context.Context, dollar_1 string) (GetMerchantLocationByIDRow, error) {
-	row := q.db.QueryRow(ctx, getMerchantLocationByID, dollar_1)
-	var i GetMerchantLocationByIDRow
-	err := row.Scan(&i.ID, &i.Lat, &i.Long)
-	return i, err
+func (q *Queries) GetEstimateMerchants(ctx context.Context, arg GetEstimateMerchantsParams) ([]GetEstimateMerchantsRow, error) {
+	rows, err := q.db.Query(ctx, getEstimateMerchants, arg.UserLong, arg.UserLat, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEstimateMerchantsRow
+	for rows.Next() {
+		var i GetEstimateMerchantsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchantCategory,
+			&i.Lat,
+			&i.Long,
+			&i.TimeZone,
+			&i.Deliverable,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMerchantCategorySettings = `-- name: GetMerchantCategorySettings :many
+SELECT
+  merchant_category,
+  prep_time_minutes,
+  base_delivery_fee,
+  per_km_fee,
+  small_order_threshold,
+  small_order_surcharge,
+  service_fee_percent
+FROM merchant_category_settings
+`
+
+func (q *Queries) GetMerchantCategorySettings(ctx context.Context) ([]MerchantCategorySetting, error) {
+	rows, err := q.db.Query(ctx, getMerchantCategorySettings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MerchantCategorySetting
+	for rows.Next() {
+		var i MerchantCategorySetting
+		if err := rows.Scan(
+			&i.MerchantCategory,
+			&i.PrepTimeMinutes,
+			&i.BaseDeliveryFee,
+			&i.PerKmFee,
+			&i.SmallOrderThreshold,
+			&i.SmallOrderSurcharge,
+			&i.ServiceFeePercent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
@@ -21,6 +21,7 @@ WHERE mi.merchant_id = $1
     $4::text IS NULL
     OR LOWER(mi.name) LIKE LOWER('%' || $4 || '%')
   )
+  AND (mi.deleted_at IS NOT NULL) = $5::bool
 `
 
 type CountMerchantItemsParams struct {
@@ -28,6 +29,7 @@ type CountMerchantItemsParams struct {
 	ItemID          pgtype.Text
 	ProductCategory pgtype.Text
 	Name            pgtype.Text
+	Deleted         bool
 }
 
 func (q *Queries) CountMerchantItems(ctx context.Context, arg CountMerchantItemsParams) (int64, error) {
@@ -36,6 +38,7 @@ func (q *Queries) CountMerchantItems(ctx context.Context, arg CountMerchantItems
 		arg.ItemID,
 		arg.ProductCategory,
 		arg.Name,
+		arg.Deleted,
 	)
 	var count int64
 	err := row.Scan(&count)
@@ -52,16 +55,29 @@ WHERE
     $3::text IS NULL
     OR LOWER(m.name) LIKE LOWER('%' || $3 || '%')
   )
+  AND (
+    $4::float8 IS NULL
+    OR (m.rating_count > 0 AND m.rating_sum::float8 / m.rating_count >= $4)
+  )
+  AND (m.deleted_at IS NOT NULL) = $5::bool
 `
 
 type CountMerchantsParams struct {
 	MerchantID       pgtype.Text
 	MerchantCategory pgtype.Text
 	Name             pgtype.Text
+	MinRating        pgtype.Float8
+	Deleted          bool
 }
 
 func (q *Queries) CountMerchants(ctx context.Context, arg CountMerchantsParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countMerchants, arg.MerchantID, arg.MerchantCategory, arg.Name)
+	row := q.db.QueryRow(ctx, countMerchants,
+		arg.MerchantID,
+		arg.MerchantCategory,
+		arg.Name,
+		arg.MinRating,
+		arg.Deleted,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -69,9 +85,9 @@ func (q *Queries) CountMerchants(ctx context.Context, arg CountMerchantsParams)
 
 const createMerchant = `-- name: CreateMerchant :one
 INSERT INTO merchants (
-  name, merchant_category, image_url, location
+  name, merchant_category, image_url, location, delivery_radius_m
 ) VALUES (
-  $1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326)
+  $1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326), $6
 ) RETURNING id
 `
 
@@ -81,6 +97,7 @@ type CreateMerchantParams struct {
 	ImageUrl         string
 	StMakepoint      interface{}
 	StMakepoint_2    interface{}
+	DeliveryRadiusM  int32
 }
 
 func (q *Queries) CreateMerchant(ctx context.Context, arg CreateMerchantParams) (pgtype.UUID, error) {
@@ -90,6 +107,7 @@ func (q *Queries) CreateMerchant(ctx context.Context, arg CreateMerchantParams)
 		arg.ImageUrl,
 		arg.StMakepoint,
 		arg.StMakepoint_2,
+		arg.DeliveryRadiusM,
 	)
 	var id pgtype.UUID
 	err := row.Scan(&id)
@@ -125,88 +143,57 @@ func (q *Queries) CreateMerchantItem(ctx context.Context, arg CreateMerchantItem
 	return id, err
 }
 
-const getMerchantByID = `-- name: GetMerchantByID :one
-SELECT EXISTS(SELECT 1 FROM merchants WHERE id = $1)
+const deleteMerchant = `-- name: DeleteMerchant :execrows
+UPDATE merchants SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL
 `
 
-func (q *Queries) GetMerchantByID(ctx context.Context, id pgtype.UUID) (bool, error) {
-	row := q.db.QueryRow(ctx, getMerchantByID, id)
-	var exists bool
-	err := row.Scan(&exists)
-	return exists, err
+func (q *Queries) DeleteMerchant(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteMerchant, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const getMerchantDetailsByID = `-- name: GetMerchantDetailsByID :one
-SELECT
-  m.id,
-  m.name,
-  m.merchant_category,
-  COALESCE(m.image_url, '') as image_url,
-  ST_Y(m.location::geometry) as lat,
-  ST_X(m.location::geometry) as long,
-  m.created_at
-FROM merchants m
-WHERE m.id = $1::uuid
+const deleteMerchantItem = `-- name: DeleteMerchantItem :execrows
+UPDATE merchant_items SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND merchant_id = $2 AND deleted_at IS NULL
 `
 
-type GetMerchantDetailsByIDRow struct {
-	ID               pgtype.UUID
-	Name             string
-	MerchantCategory MerchantCategory
-	ImageUrl         string
-	Lat              interface{}
-	Long             interface{}
-	CreatedAt        pgtype.Timestamptz
+type DeleteMerchantItemParams struct {
+	ID         pgtype.UUID
+	MerchantID pgtype.UUID
 }
 
-func (q *Queries) GetMerchantDetailsByID(ctx context.Context, id pgtype.UUID) (GetMerchantDetailsByIDRow, error) {
-	row := q.db.QueryRow(ctx, getMerchantDetailsByID, id)
-	var i GetMerchantDetailsByIDRow
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.MerchantCategory,
-		&i.ImageUrl,
-		&i.Lat,
-		&i.Long,
-		&i.CreatedAt,
-	)
-	return i, err
+func (q *Queries) DeleteMerchantItem(ctx context.Context, arg DeleteMerchantItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteMerchantItem, arg.ID, arg.MerchantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const getMerchantItemByID = `-- name: GetMerchantItemByID :one
-SELECT
-  mi.id,
-  mi.name,
-  mi.product_category,
-  mi.price,
-  COALESCE(mi.image_url, '') as image_url,
-  mi.created_at
-FROM merchant_items mi
-WHERE mi.id = $1::uuid
+const getActiveMerchantByID = `-- name: GetActiveMerchantByID :one
+SELECT EXISTS(SELECT 1 FROM merchants WHERE id = $1 AND deleted_at IS NULL)
 `
 
-type GetMerchantItemByIDRow struct {
-	ID              pgtype.UUID
-	Name            string
-	ProductCategory ProductCategory
-	Price           int32
-	ImageUrl        string
-	CreatedAt       pgtype.Timestamptz
+func (q *Queries) GetActiveMerchantByID(ctx context.Context, id pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, getActiveMerchantByID, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
 }
 
-func (q *Queries) GetMerchantItemByID(ctx context.Context, id pgtype.UUID) (GetMerchantItemByIDRow, error) {
-	row := q.db.QueryRow(ctx, getMerchantItemByID, id)
-	var i GetMerchantItemByIDRow
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.ProductCategory,
-		&i.Price,
-		&i.ImageUrl,
-		&i.CreatedAt,
-	)
-	return i, err
+const getMerchantByID = `-- name: GetMerchantByID :one
+SELECT EXISTS(SELECT 1 FROM merchants WHERE id = $1)
+`
+
+func (q *Queries) GetMerchantByID(ctx context.Context, id pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, getMerchantByID, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
 }
 
 const getMerchantItems = `-- name: GetMerchantItems :many
@@ -216,7 +203,10 @@ SELECT
   mi.product_category,
   mi.price,
   COALESCE(mi.image_url, '') as image_url,
-  mi.created_at
+  mi.created_at,
+  mi.available,
+  mi.stock,
+  mi.deleted_at
 FROM merchant_items mi
 WHERE mi.merchant_id = $1
   AND ($2::text IS NULL OR mi.id::text = $2)
@@ -225,11 +215,12 @@ WHERE mi.merchant_id = $1
     $4::text IS NULL
     OR LOWER(mi.name) LIKE LOWER('%' || $4 || '%')
   )
+  AND (mi.deleted_at IS NOT NULL) = $5::bool
 ORDER BY
-  CASE WHEN $5 = 'asc' THEN mi.created_at END ASC,
-  CASE WHEN $5 = 'desc' THEN mi.created_at END DESC,
+  CASE WHEN $6 = 'asc' THEN mi.created_at END ASC,
+  CASE WHEN $6 = 'desc' THEN mi.created_at END DESC,
   mi.id ASC
-LIMIT $7::int OFFSET $6::int
+LIMIT $8::int OFFSET $7::int
 `
 
 type GetMerchantItemsParams struct {
@@ -237,6 +228,7 @@ type GetMerchantItemsParams struct {
 	ItemID          pgtype.Text
 	ProductCategory pgtype.Text
 	Name            pgtype.Text
+	Deleted         bool
 	CreatedAt       interface{}
 	OffsetVal       int32
 	LimitVal        int32
@@ -249,6 +241,9 @@ type GetMerchantItemsRow struct {
 	Price           int32
 	ImageUrl        string
 	CreatedAt       pgtype.Timestamptz
+	Available       bool
+	Stock           pgtype.Int4
+	DeletedAt       pgtype.Timestamptz
 }
 
 func (q *Queries) GetMerchantItems(ctx context.Context, arg GetMerchantItemsParams) ([]GetMerchantItemsRow, error) {
@@ -257,6 +252,7 @@ func (q *Queries) GetMerchantItems(ctx context.Context, arg GetMerchantItemsPara
 		arg.ItemID,
 		arg.ProductCategory,
 		arg.Name,
+		arg.Deleted,
 		arg.CreatedAt,
 		arg.OffsetVal,
 		arg.LimitVal,
@@ -275,6 +271,9 @@ func (q *Queries) GetMerchantItems(ctx context.Context, arg GetMerchantItemsPara
 			&i.Price,
 			&i.ImageUrl,
 			&i.CreatedAt,
+			&i.Available,
+			&i.Stock,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -294,7 +293,11 @@ SELECT
   COALESCE(m.image_url, '') as image_url,
   ST_Y(m.location::geometry) as lat,
   ST_X(m.location::geometry) as long,
-  m.created_at
+  m.created_at,
+  m.delivery_radius_m,
+  m.rating_count,
+  COALESCE(ROUND(m.rating_sum::numeric / NULLIF(m.rating_count, 0), 2), 0)::float8 AS rating_average,
+  m.deleted_at
 FROM merchants m
 WHERE
   ($1::text IS NULL OR m.id::text = $1)
@@ -303,17 +306,27 @@ WHERE
     $3::text IS NULL
     OR LOWER(m.name) LIKE LOWER('%' || $3 || '%')
   )
+  AND (
+    $4::float8 IS NULL
+    OR (m.rating_count > 0 AND m.rating_sum::float8 / m.rating_count >= $4)
+  )
+  AND (m.deleted_at IS NOT NULL) = $5::bool
 ORDER BY
-  CASE WHEN $4 = 'asc' THEN m.created_at END ASC,
-  CASE WHEN $4 = 'desc' THEN m.created_at END DESC,
+  CASE WHEN $6 = 'asc' THEN m.rating_sum::float8 / NULLIF(m.rating_count, 0) END ASC NULLS LAST,
+  CASE WHEN $6 = 'desc' THEN m.rating_sum::float8 / NULLIF(m.rating_count, 0) END DESC NULLS LAST,
+  CASE WHEN $7 = 'asc' THEN m.created_at END ASC,
+  CASE WHEN $7 = 'desc' THEN m.created_at END DESC,
   m.id ASC
-LIMIT $6::int OFFSET $5::int
+LIMIT $9::int OFFSET $8::int
 `
 
 type GetMerchantsParams struct {
 	MerchantID       pgtype.Text
 	MerchantCategory pgtype.Text
 	Name             pgtype.Text
+	MinRating        pgtype.Float8
+	Deleted          bool
+	Rating           interface{}
 	CreatedAt        interface{}
 	OffsetVal        int32
 	LimitVal         int32
@@ -327,6 +340,10 @@ type GetMerchantsRow struct {
 	Lat              interface{}
 	Long             interface{}
 	CreatedAt        pgtype.Timestamptz
+	DeliveryRadiusM  int32
+	RatingCount      int32
+	RatingAverage    float64
+	DeletedAt        pgtype.Timestamptz
 }
 
 func (q *Queries) GetMerchants(ctx context.Context, arg GetMerchantsParams) ([]GetMerchantsRow, error) {
@@ -334,6 +351,9 @@ func (q *Queries) GetMerchants(ctx context.Context, arg GetMerchantsParams) ([]G
 		arg.MerchantID,
 		arg.MerchantCategory,
 		arg.Name,
+		arg.MinRating,
+		arg.Deleted,
+		arg.Rating,
 		arg.CreatedAt,
 		arg.OffsetVal,
 		arg.LimitVal,
@@ -353,6 +373,10 @@ func (q *Queries) GetMerchants(ctx context.Context, arg GetMerchantsParams) ([]G
 			&i.Lat,
 			&i.Long,
 			&i.CreatedAt,
+			&i.DeliveryRadiusM,
+			&i.RatingCount,
+			&i.RatingAverage,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -363,3 +387,167 @@ func (q *Queries) GetMerchants(ctx context.Context, arg GetMerchantsParams) ([]G
 	}
 	return items, nil
 }
+
+const restoreMerchant = `-- name: RestoreMerchant :execrows
+UPDATE merchants SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreMerchant(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreMerchant, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreMerchantItem = `-- name: RestoreMerchantItem :execrows
+UPDATE merchant_items SET deleted_at = NULL
+WHERE id = $1 AND merchant_id = $2 AND deleted_at IS NOT NULL
+`
+
+type RestoreMerchantItemParams struct {
+	ID         pgtype.UUID
+	MerchantID pgtype.UUID
+}
+
+func (q *Queries) RestoreMerchantItem(ctx context.Context, arg RestoreMerchantItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreMerchantItem, arg.ID, arg.MerchantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateMerchant = `-- name: UpdateMerchant :one
+UPDATE merchants
+SET
+  name = COALESCE($1::text, name),
+  merchant_category = COALESCE($2::merchant_category, merchant_category),
+  image_url = COALESCE($3::text, image_url),
+  location = CASE
+    WHEN $4::float8 IS NULL OR $5::float8 IS NULL THEN location
+    ELSE ST_SetSRID(ST_MakePoint($5::float8, $4::float8), 4326)
+  END,
+  delivery_radius_m = COALESCE($6::int, delivery_radius_m)
+WHERE id = $7 AND deleted_at IS NULL
+RETURNING
+  id,
+  name,
+  merchant_category,
+  COALESCE(image_url, '') AS image_url,
+  ST_Y(location::geometry)::float8 AS lat,
+  ST_X(location::geometry)::float8 AS long,
+  created_at,
+  delivery_radius_m
+`
+
+type UpdateMerchantParams struct {
+	Name             pgtype.Text
+	MerchantCategory NullMerchantCategory
+	ImageUrl         pgtype.Text
+	Lat              pgtype.Float8
+	Long             pgtype.Float8
+	DeliveryRadiusM  pgtype.Int4
+	ID               pgtype.UUID
+}
+
+type UpdateMerchantRow struct {
+	ID               pgtype.UUID
+	Name             string
+	MerchantCategory MerchantCategory
+	ImageUrl         string
+	Lat              float64
+	Long             float64
+	CreatedAt        pgtype.Timestamptz
+	DeliveryRadiusM  int32
+}
+
+// Fields left null keep their value, the location only moves when both
+// coordinates are given
+func (q *Queries) UpdateMerchant(ctx context.Context, arg UpdateMerchantParams) (UpdateMerchantRow, error) {
+	row := q.db.QueryRow(ctx, updateMerchant,
+		arg.Name,
+		arg.MerchantCategory,
+		arg.ImageUrl,
+		arg.Lat,
+		arg.Long,
+		arg.DeliveryRadiusM,
+		arg.ID,
+	)
+	var i UpdateMerchantRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.MerchantCategory,
+		&i.ImageUrl,
+		&i.Lat,
+		&i.Long,
+		&i.CreatedAt,
+		&i.DeliveryRadiusM,
+	)
+	return i, err
+}
+
+const updateMerchantItem = `-- name: UpdateMerchantItem :one
+UPDATE merchant_items
+SET
+  name = COALESCE($1::text, name),
+  product_category = COALESCE($2::product_category, product_category),
+  price = COALESCE($3::int, price),
+  image_url = COALESCE($4::text, image_url)
+WHERE id = $5 AND merchant_id = $6 AND deleted_at IS NULL
+RETURNING
+  id,
+  name,
+  product_category,
+  price,
+  COALESCE(image_url, '') AS image_url,
+  created_at,
+  available,
+  stock
+`
+
+type UpdateMerchantItemParams struct {
+	Name            pgtype.Text
+	ProductCategory NullProductCategory
+	Price           pgtype.Int4
+	ImageUrl        pgtype.Text
+	ID              pgtype.UUID
+	MerchantID      pgtype.UUID
+}
+
+type UpdateMerchantItemRow struct {
+	ID              pgtype.UUID
+	Name            string
+	ProductCategory ProductCategory
+	Price           int32
+	ImageUrl        string
+	CreatedAt       pgtype.Timestamptz
+	Available       bool
+	Stock           pgtype.Int4
+}
+
+// Fields left null keep their value
+func (q *Queries) UpdateMerchantItem(ctx context.Context, arg UpdateMerchantItemParams) (UpdateMerchantItemRow, error) {
+	row := q.db.QueryRow(ctx, updateMerchantItem,
+		arg.Name,
+		arg.ProductCategory,
+		arg.Price,
+		arg.ImageUrl,
+		arg.ID,
+		arg.MerchantID,
+	)
+	var i UpdateMerchantItemRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProductCategory,
+		&i.Price,
+		&i.ImageUrl,
+		&i.CreatedAt,
+		&i.Available,
+		&i.Stock,
+	)
+	return i, err
+}
@@ -11,6 +11,92 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type LedgerAccountType string
+
+const (
+	LedgerAccountTypeWallet LedgerAccountType = "wallet"
+	LedgerAccountTypeTopUp  LedgerAccountType = "top_up"
+	LedgerAccountTypeOrders LedgerAccountType = "orders"
+)
+
+func (e *LedgerAccountType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LedgerAccountType(s)
+	case string:
+		*e = LedgerAccountType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LedgerAccountType: %T", src)
+	}
+	return nil
+}
+
+type NullLedgerAccountType struct {
+	LedgerAccountType LedgerAccountType
+	Valid             bool // Valid is true if LedgerAccountType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLedgerAccountType) Scan(value interface{}) error {
+	if value == nil {
+		ns.LedgerAccountType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LedgerAccountType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLedgerAccountType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LedgerAccountType), nil
+}
+
+type LedgerTransactionKind string
+
+const (
+	LedgerTransactionKindTopUp        LedgerTransactionKind = "top_up"
+	LedgerTransactionKindOrderPayment LedgerTransactionKind = "order_payment"
+	LedgerTransactionKindOrderRefund  LedgerTransactionKind = "order_refund"
+)
+
+func (e *LedgerTransactionKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LedgerTransactionKind(s)
+	case string:
+		*e = LedgerTransactionKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LedgerTransactionKind: %T", src)
+	}
+	return nil
+}
+
+type NullLedgerTransactionKind struct {
+	LedgerTransactionKind LedgerTransactionKind
+	Valid                 bool // Valid is true if LedgerTransactionKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLedgerTransactionKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.LedgerTransactionKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LedgerTransactionKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLedgerTransactionKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LedgerTransactionKind), nil
+}
+
 type MerchantCategory string
 
 const (
@@ -57,6 +143,145 @@ func (ns NullMerchantCategory) Value() (driver.Value, error) {
 	return string(ns.MerchantCategory), nil
 }
 
+type OrderMerchantStatus string
+
+const (
+	OrderMerchantStatusPending  OrderMerchantStatus = "pending"
+	OrderMerchantStatusAccepted OrderMerchantStatus = "accepted"
+	OrderMerchantStatusReady    OrderMerchantStatus = "ready"
+	OrderMerchantStatusRejected OrderMerchantStatus = "rejected"
+)
+
+func (e *OrderMerchantStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderMerchantStatus(s)
+	case string:
+		*e = OrderMerchantStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderMerchantStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrderMerchantStatus struct {
+	OrderMerchantStatus OrderMerchantStatus
+	Valid               bool // Valid is true if OrderMerchantStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderMerchantStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderMerchantStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderMerchantStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderMerchantStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderMerchantStatus), nil
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusPendingPayment OrderStatus = "pending_payment"
+	OrderStatusPlaced         OrderStatus = "placed"
+	OrderStatusAccepted       OrderStatus = "accepted"
+	OrderStatusPreparing      OrderStatus = "preparing"
+	OrderStatusPickedUp       OrderStatus = "picked_up"
+	OrderStatusDelivered      OrderStatus = "delivered"
+	OrderStatusCancelled      OrderStatus = "cancelled"
+	OrderStatusRejected       OrderStatus = "rejected"
+)
+
+func (e *OrderStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderStatus(s)
+	case string:
+		*e = OrderStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrderStatus struct {
+	OrderStatus OrderStatus
+	Valid       bool // Valid is true if OrderStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderStatus), nil
+}
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending       PaymentStatus = "pending"
+	PaymentStatusAuthorized    PaymentStatus = "authorized"
+	PaymentStatusCaptured      PaymentStatus = "captured"
+	PaymentStatusFailed        PaymentStatus = "failed"
+	PaymentStatusCancelled     PaymentStatus = "cancelled"
+	PaymentStatusRefundPending PaymentStatus = "refund_pending"
+	PaymentStatusRefunded      PaymentStatus = "refunded"
+)
+
+func (e *PaymentStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PaymentStatus(s)
+	case string:
+		*e = PaymentStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PaymentStatus: %T", src)
+	}
+	return nil
+}
+
+type NullPaymentStatus struct {
+	PaymentStatus PaymentStatus
+	Valid         bool // Valid is true if PaymentStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPaymentStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.PaymentStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PaymentStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPaymentStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PaymentStatus), nil
+}
+
 type ProductCategory string
 
 const (
@@ -105,8 +330,9 @@ func (ns NullProductCategory) Value() (driver.Value, error) {
 type UserRole string
 
 const (
-	UserRoleUser  UserRole = "user"
-	UserRoleAdmin UserRole = "admin"
+	UserRoleUser    UserRole = "user"
+	UserRoleAdmin   UserRole = "admin"
+	UserRoleCourier UserRole = "courier"
 )
 
 func (e *UserRole) Scan(src interface{}) error {
@@ -151,6 +377,29 @@ type CalculatedEstimate struct {
 	EstimatedDeliveryTimeMinutes int32
 	EstimateData                 []byte
 	CreatedAt                    pgtype.Timestamptz
+	ExpiresAt                    pgtype.Timestamptz
+}
+
+type Courier struct {
+	UserID            pgtype.UUID
+	Available         bool
+	Location          interface{}
+	LocationUpdatedAt pgtype.Timestamptz
+}
+
+type CourierLocation struct {
+	ID         int64
+	CourierID  pgtype.UUID
+	Location   interface{}
+	RecordedAt pgtype.Timestamptz
+}
+
+type DeliveryZone struct {
+	ID              pgtype.UUID
+	Name            string
+	Area            interface{}
+	DeliveryRadiusM int32
+	CreatedAt       pgtype.Timestamptz
 }
 
 type Image struct {
@@ -161,6 +410,45 @@ type Image struct {
 	CreatedAt pgtype.Timestamptz
 }
 
+type ItemOption struct {
+	ID         pgtype.UUID
+	GroupID    pgtype.UUID
+	Name       string
+	PriceDelta int32
+	Position   int32
+}
+
+type ItemOptionGroup struct {
+	ID            pgtype.UUID
+	ItemID        pgtype.UUID
+	Name          string
+	MinSelections int32
+	MaxSelections int32
+	Position      int32
+}
+
+type LedgerAccount struct {
+	ID        pgtype.UUID
+	Type      LedgerAccountType
+	UserID    pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type LedgerEntry struct {
+	ID            int64
+	TransactionID pgtype.UUID
+	AccountID     pgtype.UUID
+	Amount        int64
+}
+
+type LedgerTransaction struct {
+	ID        pgtype.UUID
+	Kind      LedgerTransactionKind
+	OrderID   pgtype.UUID
+	CreatedBy pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
 type Merchant struct {
 	ID               pgtype.UUID
 	Name             string
@@ -168,6 +456,27 @@ type Merchant struct {
 	Location         interface{}
 	CreatedAt        pgtype.Timestamptz
 	ImageUrl         string
+	DeliveryRadiusM  int32
+	TimeZone         string
+	RatingSum        int32
+	RatingCount      int32
+	DeletedAt        pgtype.Timestamptz
+}
+
+type MerchantCategorySetting struct {
+	MerchantCategory    MerchantCategory
+	PrepTimeMinutes     int32
+	BaseDeliveryFee     int32
+	PerKmFee            int32
+	SmallOrderThreshold int32
+	SmallOrderSurcharge int32
+	ServiceFeePercent   int32
+}
+
+type MerchantHoliday struct {
+	MerchantID  pgtype.UUID
+	HolidayDate pgtype.Date
+	Note        pgtype.Text
 }
 
 type MerchantItem struct {
@@ -178,6 +487,26 @@ type MerchantItem struct {
 	Price           int32
 	CreatedAt       pgtype.Timestamptz
 	ImageUrl        string
+	Available       bool
+	Stock           pgtype.Int4
+	DeletedAt       pgtype.Timestamptz
+}
+
+type MerchantOpeningHour struct {
+	MerchantID pgtype.UUID
+	DayOfWeek  int16
+	OpensAt    pgtype.Time
+	ClosesAt   pgtype.Time
+}
+
+type MerchantReview struct {
+	OrderID    pgtype.UUID
+	MerchantID pgtype.UUID
+	UserID     pgtype.UUID
+	Rating     int16
+	Comment    pgtype.Text
+	ImageUrl   pgtype.Text
+	CreatedAt  pgtype.Timestamptz
 }
 
 type Order struct {
@@ -185,6 +514,113 @@ type Order struct {
 	UserID               pgtype.UUID
 	CalculatedEstimateID pgtype.UUID
 	CreatedAt            pgtype.Timestamptz
+	DuplicateOf          pgtype.UUID
+	Status               OrderStatus
+	CourierID            pgtype.UUID
+	CourierAcceptedAt    pgtype.Timestamptz
+}
+
+type OrderCancellation struct {
+	OrderID      pgtype.UUID
+	Reason       pgtype.Text
+	Fee          int32
+	RefundAmount int32
+	CancelledBy  pgtype.UUID
+	CreatedAt    pgtype.Timestamptz
+}
+
+type OrderEvent struct {
+	ID        int64
+	OrderID   pgtype.UUID
+	Type      string
+	Data      []byte
+	CreatedAt pgtype.Timestamptz
+}
+
+type OrderIdempotencyKey struct {
+	UserID         pgtype.UUID
+	IdempotencyKey string
+	RequestHash    string
+	OrderID        pgtype.UUID
+	CreatedAt      pgtype.Timestamptz
+}
+
+type OrderItem struct {
+	OrderID         pgtype.UUID
+	MerchantID      pgtype.UUID
+	ItemID          pgtype.UUID
+	Quantity        int32
+	Position        int32
+	Name            string
+	ProductCategory ProductCategory
+	UnitPrice       int32
+	ImageUrl        string
+	ItemCreatedAt   pgtype.Timestamptz
+}
+
+type OrderItemOption struct {
+	OrderID      pgtype.UUID
+	MerchantID   pgtype.UUID
+	ItemPosition int32
+	Position     int32
+	OptionID     pgtype.UUID
+	GroupName    string
+	Name         string
+	PriceDelta   int32
+}
+
+type OrderItemReservation struct {
+	OrderID    pgtype.UUID
+	ItemID     pgtype.UUID
+	Quantity   int32
+	CreatedAt  pgtype.Timestamptz
+	ReleasedAt pgtype.Timestamptz
+}
+
+type OrderMerchant struct {
+	OrderID           pgtype.UUID
+	MerchantID        pgtype.UUID
+	Position          int32
+	Subtotal          int32
+	Status            OrderMerchantStatus
+	UpdatedAt         pgtype.Timestamptz
+	Name              string
+	MerchantCategory  MerchantCategory
+	ImageUrl          string
+	Location          interface{}
+	MerchantCreatedAt pgtype.Timestamptz
+	PickedUpAt        pgtype.Timestamptz
+}
+
+type OrderStatusHistory struct {
+	ID         pgtype.UUID
+	OrderID    pgtype.UUID
+	FromStatus NullOrderStatus
+	ToStatus   OrderStatus
+	ActorID    pgtype.UUID
+	ActorRole  NullUserRole
+	CreatedAt  pgtype.Timestamptz
+}
+
+type Payment struct {
+	ID           pgtype.UUID
+	OrderID      pgtype.UUID
+	Provider     string
+	Method       string
+	IntentID     pgtype.Text
+	ClientSecret pgtype.Text
+	Amount       int32
+	Status       PaymentStatus
+	RefundAmount pgtype.Int4
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+}
+
+type PaymentWebhookEvent struct {
+	Provider   string
+	EventID    string
+	Type       string
+	ReceivedAt pgtype.Timestamptz
 }
 
 type User struct {
@@ -15,27 +15,44 @@ const countNearbyMerchants = `-- name: CountNearbyMerchants :one
 SELECT COUNT(*)
 FROM merchants m
 WHERE
-  ($1::text IS NULL OR m.id::text = $1)
-  AND ($2::text IS NULL OR m.merchant_category::text = $2)
+  m.deleted_at IS NULL
+  AND merchant_can_deliver(m.location, m.delivery_radius_m, ST_SetSRID(ST_MakePoint($1, $2), 4326))
+  AND ($3::text IS NULL OR m.id::text = $3)
+  AND ($4::text IS NULL OR m.merchant_category::text = $4)
   AND (
-    $3::text IS NULL
-    OR LOWER(m.name) LIKE LOWER('%' || $3 || '%')
+    $5::text IS NULL
+    OR LOWER(m.name) LIKE LOWER('%' || $5 || '%')
     OR EXISTS (
       SELECT 1 FROM merchant_items mi
       WHERE mi.merchant_id = m.id
-        AND LOWER(mi.name) LIKE LOWER('%' || $3 || '%')
+        AND mi.deleted_at IS NULL
+        AND LOWER(mi.name) LIKE LOWER('%' || $5 || '%')
     )
   )
+  AND (
+    $6::float8 IS NULL
+    OR (m.rating_count > 0 AND m.rating_sum::float8 / m.rating_count >= $6)
+  )
 `
 
 type CountNearbyMerchantsParams struct {
+	Long             interface{}
+	Lat              interface{}
 	MerchantID       pgtype.Text
 	MerchantCategory pgtype.Text
 	Name             pgtype.Text
+	MinRating        pgtype.Float8
 }
 
 func (q *Queries) CountNearbyMerchants(ctx context.Context, arg CountNearbyMerchantsParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countNearbyMerchants, arg.MerchantID, arg.MerchantCategory, arg.Name)
+	row := q.db.QueryRow(ctx, countNearbyMerchants,
+		arg.Long,
+		arg.Lat,
+		arg.MerchantID,
+		arg.MerchantCategory,
+		arg.Name,
+		arg.MinRating,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -50,10 +67,15 @@ SELECT
   ST_Y(m.location::geometry) AS lat,
   ST_X(m.location::geometry) AS long,
   m.created_at,
+  m.time_zone,
+  m.rating_count,
+  COALESCE(ROUND(m.rating_sum::numeric / NULLIF(m.rating_count, 0), 2), 0)::float8 AS rating_average,
   ST_DistanceSphere(m.location, ST_SetSRID(ST_MakePoint($1, $2), 4326)) AS distance
 FROM merchants m
 WHERE
-  ($3::text IS NULL OR m.id::text = $3)
+  m.deleted_at IS NULL
+  AND merchant_can_deliver(m.location, m.delivery_radius_m, ST_SetSRID(ST_MakePoint($1, $2), 4326))
+  AND ($3::text IS NULL OR m.id::text = $3)
   AND ($4::text IS NULL OR m.merchant_category::text = $4)
   AND (
     $5::text IS NULL
@@ -61,11 +83,20 @@ WHERE
     OR EXISTS (
       SELECT 1 FROM merchant_items mi
       WHERE mi.merchant_id = m.id
+        AND mi.deleted_at IS NULL
         AND LOWER(mi.name) LIKE LOWER('%' || $5 || '%')
     )
   )
-ORDER BY distance ASC, m.id ASC
-LIMIT $7::int OFFSET $6::int
+  AND (
+    $6::float8 IS NULL
+    OR (m.rating_count > 0 AND m.rating_sum::float8 / m.rating_count >= $6)
+  )
+ORDER BY
+  CASE WHEN $7 = 'asc' THEN m.rating_sum::float8 / NULLIF(m.rating_count, 0) END ASC NULLS LAST,
+  CASE WHEN $7 = 'desc' THEN m.rating_sum::float8 / NULLIF(m.rating_count, 0) END DESC NULLS LAST,
+  distance ASC,
+  m.id ASC
+LIMIT $9::int OFFSET $8::int
 `
 
 type GetNearbyMerchantsParams struct {
@@ -74,6 +105,8 @@ type GetNearbyMerchantsParams struct {
 	MerchantID       pgtype.Text
 	MerchantCategory pgtype.Text
 	Name             pgtype.Text
+	MinRating        pgtype.Float8
+	Rating           interface{}
 	RowOffset        int32
 	RowLimit         int32
 }
@@ -86,6 +119,9 @@ type GetNearbyMerchantsRow struct {
 	Lat              interface{}
 	Long             interface{}
 	CreatedAt        pgtype.Timestamptz
+	TimeZone         string
+	RatingCount      int32
+	RatingAverage    float64
 	Distance         interface{}
 }
 
@@ -96,6 +132,8 @@ func (q *Queries) GetNearbyMerchants(ctx context.Context, arg GetNearbyMerchants
 		arg.MerchantID,
 		arg.MerchantCategory,
 		arg.Name,
+		arg.MinRating,
+		arg.Rating,
 		arg.RowOffset,
 		arg.RowLimit,
 	)
@@ -114,6 +152,9 @@ func (q *Queries) GetNearbyMerchants(ctx context.Context, arg GetNearbyMerchants
 			&i.Lat,
 			&i.Long,
 			&i.CreatedAt,
+			&i.TimeZone,
+			&i.RatingCount,
+			&i.RatingAverage,
 			&i.Distance,
 		); err != nil {
 			return nil, err
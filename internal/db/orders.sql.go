@@ -11,11 +11,67 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countUserOrders = `-- name: CountUserOrders :one
+SELECT COUNT(*)
+FROM orders o
+WHERE o.user_id = $1
+  AND (
+    $2::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND om.merchant_id::text = $2
+    )
+  )
+  AND (
+    $3::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND om.merchant_category::text = $3
+    )
+  )
+  AND (
+    $4::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND LOWER(om.name) LIKE LOWER('%' || $4 || '%')
+    )
+    OR EXISTS (
+      SELECT 1 FROM order_items oi
+      WHERE oi.order_id = o.id AND LOWER(oi.name) LIKE LOWER('%' || $4 || '%')
+    )
+  )
+  AND ($5::timestamptz IS NULL OR o.created_at >= $5)
+  AND ($6::timestamptz IS NULL OR o.created_at < $6)
+`
+
+type CountUserOrdersParams struct {
+	UserID           pgtype.UUID
+	MerchantID       pgtype.Text
+	MerchantCategory pgtype.Text
+	Name             pgtype.Text
+	CreatedFrom      pgtype.Timestamptz
+	CreatedTo        pgtype.Timestamptz
+}
+
+func (q *Queries) CountUserOrders(ctx context.Context, arg CountUserOrdersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserOrders,
+		arg.UserID,
+		arg.MerchantID,
+		arg.MerchantCategory,
+		arg.Name,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createCalculatedEstimate = `-- name: CreateCalculatedEstimate :one
 INSERT INTO calculated_estimates (
-  user_id, total_price, estimated_delivery_time_minutes, estimate_data
+  user_id, total_price, estimated_delivery_time_minutes, estimate_data, expires_at
 ) VALUES (
-  $1::uuid, $2, $3, $4
+  $1::uuid, $2, $3, $4, $5
 ) RETURNING id
 `
 
@@ -24,6 +80,7 @@ type CreateCalculatedEstimateParams struct {
 	TotalPrice                   int32
 	EstimatedDeliveryTimeMinutes int32
 	EstimateData                 []byte
+	ExpiresAt                    pgtype.Timestamptz
 }
 
 func (q *Queries) CreateCalculatedEstimate(ctx context.Context, arg CreateCalculatedEstimateParams) (pgtype.UUID, error) {
@@ -32,6 +89,7 @@ func (q *Queries) CreateCalculatedEstimate(ctx context.Context, arg CreateCalcul
 		arg.TotalPrice,
 		arg.EstimatedDeliveryTimeMinutes,
 		arg.EstimateData,
+		arg.ExpiresAt,
 	)
 	var id pgtype.UUID
 	err := row.Scan(&id)
@@ -40,24 +98,50 @@ func (q *Queries) CreateCalculatedEstimate(ctx context.Context, arg CreateCalcul
 
 const createOrder = `-- name: CreateOrder :one
 INSERT INTO orders (
-  user_id, calculated_estimate_id
+  user_id, calculated_estimate_id, status
 ) VALUES (
-  $1::uuid, $2::uuid
+  $1::uuid, $2::uuid, $3
 ) RETURNING id
 `
 
 type CreateOrderParams struct {
 	UserID               pgtype.UUID
 	CalculatedEstimateID pgtype.UUID
+	Status               OrderStatus
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, createOrder, arg.UserID, arg.CalculatedEstimateID)
+	row := q.db.QueryRow(ctx, createOrder, arg.UserID, arg.CalculatedEstimateID, arg.Status)
 	var id pgtype.UUID
 	err := row.Scan(&id)
 	return id, err
 }
 
+const createOrderIdempotencyKey = `-- name: CreateOrderIdempotencyKey :exec
+INSERT INTO order_idempotency_keys (
+  user_id, idempotency_key, request_hash, order_id
+) VALUES (
+  $1::uuid, $2, $3, $4::uuid
+)
+`
+
+type CreateOrderIdempotencyKeyParams struct {
+	UserID         pgtype.UUID
+	IdempotencyKey string
+	RequestHash    string
+	OrderID        pgtype.UUID
+}
+
+func (q *Queries) CreateOrderIdempotencyKey(ctx context.Context, arg CreateOrderIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, createOrderIdempotencyKey,
+		arg.UserID,
+		arg.IdempotencyKey,
+		arg.RequestHash,
+		arg.OrderID,
+	)
+	return err
+}
+
 const getCalculatedEstimateByID = `-- name: GetCalculatedEstimateByID :one
 SELECT 
   id,
@@ -65,7 +149,8 @@ SELECT
   total_price,
   estimated_delivery_time_minutes,
   estimate_data,
-  created_at
+  created_at,
+  expires_at
 FROM calculated_estimates
 WHERE id = $1::uuid
 `
@@ -80,44 +165,260 @@ func (q *Queries) GetCalculatedEstimateByID(ctx context.Context, id pgtype.UUID)
 		&i.EstimatedDeliveryTimeMinutes,
 		&i.EstimateData,
 		&i.CreatedAt,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
-const getOrdersByUserID = `-- name: GetOrdersByUserID :many
-SELECT 
+const getOrderHistoryItems = `-- name: GetOrderHistoryItems :many
+SELECT
+  oi.order_id,
+  oi.merchant_id,
+  oi.item_id,
+  oi.position,
+  oi.name,
+  oi.product_category,
+  oi.unit_price,
+  oi.quantity,
+  oi.image_url,
+  oi.item_created_at
+FROM order_items oi
+WHERE oi.order_id = ANY($1::uuid[])
+ORDER BY oi.order_id, oi.merchant_id, oi.position
+`
+
+type GetOrderHistoryItemsRow struct {
+	OrderID         pgtype.UUID
+	MerchantID      pgtype.UUID
+	ItemID          pgtype.UUID
+	Position        int32
+	Name            string
+	ProductCategory ProductCategory
+	UnitPrice       int32
+	Quantity        int32
+	ImageUrl        string
+	ItemCreatedAt   pgtype.Timestamptz
+}
+
+func (q *Queries) GetOrderHistoryItems(ctx context.Context, orderIds []pgtype.UUID) ([]GetOrderHistoryItemsRow, error) {
+	rows, err := q.db.Query(ctx, getOrderHistoryItems, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderHistoryItemsRow
+	for rows.Next() {
+		var i GetOrderHistoryItemsRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.MerchantID,
+			&i.ItemID,
+			&i.Position,
+			&i.Name,
+			&i.ProductCategory,
+			&i.UnitPrice,
+			&i.Quantity,
+			&i.ImageUrl,
+			&i.ItemCreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderHistoryMerchants = `-- name: GetOrderHistoryMerchants :many
+SELECT
+  om.order_id,
+  om.merchant_id,
+  om.name,
+  om.merchant_category,
+  om.image_url,
+  ST_Y(om.location)::float8 AS lat,
+  ST_X(om.location)::float8 AS long,
+  om.merchant_created_at
+FROM order_merchants om
+WHERE om.order_id = ANY($1::uuid[])
+ORDER BY om.order_id, om.position
+`
+
+type GetOrderHistoryMerchantsRow struct {
+	OrderID           pgtype.UUID
+	MerchantID        pgtype.UUID
+	Name              string
+	MerchantCategory  MerchantCategory
+	ImageUrl          string
+	Lat               float64
+	Long              float64
+	MerchantCreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetOrderHistoryMerchants(ctx context.Context, orderIds []pgtype.UUID) ([]GetOrderHistoryMerchantsRow, error) {
+	rows, err := q.db.Query(ctx, getOrderHistoryMerchants, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderHistoryMerchantsRow
+	for rows.Next() {
+		var i GetOrderHistoryMerchantsRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.MerchantID,
+			&i.Name,
+			&i.MerchantCategory,
+			&i.ImageUrl,
+			&i.Lat,
+			&i.Long,
+			&i.MerchantCreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderIdempotencyKey = `-- name: GetOrderIdempotencyKey :one
+SELECT
+  request_hash,
+  order_id
+FROM order_idempotency_keys
+WHERE user_id = $1::uuid AND idempotency_key = $2
+`
+
+type GetOrderIdempotencyKeyParams struct {
+	UserID         pgtype.UUID
+	IdempotencyKey string
+}
+
+type GetOrderIdempotencyKeyRow struct {
+	RequestHash string
+	OrderID     pgtype.UUID
+}
+
+func (q *Queries) GetOrderIdempotencyKey(ctx context.Context, arg GetOrderIdempotencyKeyParams) (GetOrderIdempotencyKeyRow, error) {
+	row := q.db.QueryRow(ctx, getOrderIdempotencyKey, arg.UserID, arg.IdempotencyKey)
+	var i GetOrderIdempotencyKeyRow
+	err := row.Scan(&i.RequestHash, &i.OrderID)
+	return i, err
+}
+
+const getUserOrderEstimate = `-- name: GetUserOrderEstimate :one
+SELECT ce.estimate_data
+FROM orders o
+JOIN calculated_estimates ce ON ce.id = o.calculated_estimate_id
+WHERE o.id = $1 AND o.user_id = $2
+`
+
+type GetUserOrderEstimateParams struct {
+	ID     pgtype.UUID
+	UserID pgtype.UUID
+}
+
+func (q *Queries) GetUserOrderEstimate(ctx context.Context, arg GetUserOrderEstimateParams) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getUserOrderEstimate, arg.ID, arg.UserID)
+	var estimate_data []byte
+	err := row.Scan(&estimate_data)
+	return estimate_data, err
+}
+
+const getUserOrders = `-- name: GetUserOrders :many
+SELECT
   o.id,
+  o.status,
   o.created_at,
   ce.estimate_data
 FROM orders o
-JOIN calculated_estimates ce ON o.calculated_estimate_id = ce.id
-WHERE o.user_id = $1::uuid
-ORDER BY o.created_at DESC
-LIMIT $2 OFFSET $3
+JOIN calculated_estimates ce ON ce.id = o.calculated_estimate_id
+WHERE o.user_id = $1
+  AND (
+    $2::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND om.merchant_id::text = $2
+    )
+  )
+  AND (
+    $3::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND om.merchant_category::text = $3
+    )
+  )
+  AND (
+    $4::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND LOWER(om.name) LIKE LOWER('%' || $4 || '%')
+    )
+    OR EXISTS (
+      SELECT 1 FROM order_items oi
+      WHERE oi.order_id = o.id AND LOWER(oi.name) LIKE LOWER('%' || $4 || '%')
+    )
+  )
+  AND ($5::timestamptz IS NULL OR o.created_at >= $5)
+  AND ($6::timestamptz IS NULL OR o.created_at < $6)
+ORDER BY
+  CASE WHEN $7 = 'asc' THEN o.created_at END ASC,
+  CASE WHEN $7 = 'desc' THEN o.created_at END DESC,
+  o.id ASC
+LIMIT $9::int OFFSET $8::int
 `
 
-type GetOrdersByUserIDParams struct {
-	Column1 pgtype.UUID
-	Limit   int32
-	Offset  int32
+type GetUserOrdersParams struct {
+	UserID           pgtype.UUID
+	MerchantID       pgtype.Text
+	MerchantCategory pgtype.Text
+	Name             pgtype.Text
+	CreatedFrom      pgtype.Timestamptz
+	CreatedTo        pgtype.Timestamptz
+	CreatedAt        interface{}
+	OffsetVal        int32
+	LimitVal         int32
 }
 
-type GetOrdersByUserIDRow struct {
+type GetUserOrdersRow struct {
 	ID           pgtype.UUID
+	Status       OrderStatus
 	CreatedAt    pgtype.Timestamptz
 	EstimateData []byte
 }
 
-func (q *Queries) GetOrdersByUserID(ctx context.Context, arg GetOrdersByUserIDParams) ([]GetOrdersByUserIDRow, error) {
-	rows, err := q.db.Query(ctx, getOrdersByUserID, arg.Column1, arg.Limit, arg.Offset)
+// Filters match when any merchant part of the order does. name matches
+// merchant and item names.
+func (q *Queries) GetUserOrders(ctx context.Context, arg GetUserOrdersParams) ([]GetUserOrdersRow, error) {
+	rows, err := q.db.Query(ctx, getUserOrders,
+		arg.UserID,
+		arg.MerchantID,
+		arg.MerchantCategory,
+		arg.Name,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CreatedAt,
+		arg.OffsetVal,
+		arg.LimitVal,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetOrdersByUserIDRow
+	var items []GetUserOrdersRow
 	for rows.Next() {
-		var i GetOrdersByUserIDRow
-		if err := rows.Scan(&i.ID, &i.CreatedAt, &i.EstimateData); err != nil {
+		var i GetUserOrdersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.EstimateData,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -127,16 +428,3 @@ func (q *Queries) GetOrdersByUserID(ctx context.Context, arg GetOrdersByUserIDPa
 	}
 	return items, nil
 }
-
-const getOrdersCountByUserID = `-- name: GetOrdersCountByUserID :one
-SELECT COUNT(*)
-FROM orders o
-WHERE o.user_id = $1::uuid
-`
-
-func (q *Queries) GetOrdersCountByUserID(ctx context.Context, dollar_1 pgtype.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, getOrdersCountByUserID, dollar_1)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
-}
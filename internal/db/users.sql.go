@@ -64,6 +64,23 @@ func (q *Queries) GetAdminByUsername(ctx context.Context, username string) (User
 	return i, err
 }
 
+const getCourierByUsername = `-- name: GetCourierByUsername :one
+SELECT id, username, password, email, role FROM users where username = $1 AND role = 'courier'
+`
+
+func (q *Queries) GetCourierByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, getCourierByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Email,
+		&i.Role,
+	)
+	return i, err
+}
+
 const getUserByUsername = `-- name: GetUserByUsername :one
 SELECT id, username, password, email, role FROM users where username = $1 AND role = 'user'
 `
@@ -0,0 +1,65 @@
+package dto
+
+// CourierAvailabilityRequest for PUT /courier/availability. Location is the
+// courier's current position and is required to become available.
+type CourierAvailabilityRequest struct {
+	Available *bool          `json:"available" binding:"required"`
+	Location  *OrderLocation `json:"location"`
+}
+
+type CourierAvailabilityResponse struct {
+	Available bool `json:"available"`
+}
+
+// CourierStop is a merchant to pick up from, in route order
+type CourierStop struct {
+	MerchantID string        `json:"merchantId"`
+	Name       string        `json:"name"`
+	Location   OrderLocation `json:"location"`
+	Status     string        `json:"status"`
+	PickedUpAt *string       `json:"pickedUpAt"`
+}
+
+type CourierOrder struct {
+	OrderID      string        `json:"orderId"`
+	Status       string        `json:"status"`
+	AcceptedAt   *string       `json:"acceptedAt"`
+	Stops        []CourierStop `json:"stops"`
+	UserLocation OrderLocation `json:"userLocation"`
+	CreatedAt    string        `json:"createdAt"`
+}
+
+// CourierOrderResponse is returned by the courier order actions
+type CourierOrderResponse struct {
+	OrderID string `json:"orderId"`
+	Status  string `json:"status"`
+}
+
+// CourierLocationRequest for POST /courier/location, a GPS ping
+type CourierLocationRequest struct {
+	Lat  *float64 `json:"lat" binding:"required,min=-90,max=90"`
+	Long *float64 `json:"long" binding:"required,min=-180,max=180"`
+}
+
+type CourierLocationResponse struct {
+	RecordedAt string `json:"recordedAt"`
+}
+
+// CourierPositionEventData is the data of a courier_position order event
+type CourierPositionEventData struct {
+	Lat        float64 `json:"lat"`
+	Long       float64 `json:"long"`
+	RecordedAt string  `json:"recordedAt"`
+}
+
+// CourierPositionResponse for GET /users/orders/:orderId/courier.
+// RemainingMinutes covers the stops not picked up yet and the drop-off,
+// travelled at SpeedKmh.
+type CourierPositionResponse struct {
+	OrderID             string        `json:"orderId"`
+	Location            OrderLocation `json:"location"`
+	UpdatedAt           string        `json:"updatedAt"`
+	SpeedKmh            float64       `json:"speedKmh"`
+	RemainingDistanceKm float64       `json:"remainingDistanceKm"`
+	RemainingMinutes    int           `json:"remainingMinutes"`
+}
@@ -4,4 +4,55 @@ type ErrorResponse struct {
 	Success bool   `json:"success"`
 	Error   string `json:"error"`
 	Code    int    `json:"code"`
+	Reason  string `json:"reason,omitempty"`
 }
+
+// FieldError points at the request field that failed validation, for example
+// "orders[0].items[1].itemId".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is an ErrorResponse with per field details
+type ValidationErrorResponse struct {
+	ErrorResponse
+	Errors []FieldError `json:"errors"`
+}
+
+// Machine-readable values for ErrorResponse.Reason
+const (
+	ReasonEstimateNotOwned = "estimate_not_owned"
+	ReasonEstimateExpired  = "estimate_expired"
+	ReasonEstimateInvalid  = "estimate_invalid"
+	ReasonPriceChanged     = "price_changed"
+	ReasonEstimateUsed     = "estimate_already_used"
+	ReasonIdempotencyReuse = "idempotency_key_reused"
+	ReasonItemUnavailable  = "item_unavailable"
+	// ReasonInvalidTransition is returned when an order cannot move to the
+	// requested status from its current one
+	ReasonInvalidTransition = "invalid_status_transition"
+	// ReasonCancellationNotAllowed is returned once an order is picked up
+	ReasonCancellationNotAllowed = "cancellation_not_allowed"
+	// ReasonNothingToReorder is returned when none of a past order's items
+	// can be ordered again
+	ReasonNothingToReorder = "nothing_to_reorder"
+	// ReasonOrderNotDelivered is returned when reviewing an order that has
+	// not been delivered yet
+	ReasonOrderNotDelivered = "order_not_delivered"
+	// ReasonAlreadyReviewed is returned for a second review of the same
+	// merchant in an order
+	ReasonAlreadyReviewed = "already_reviewed"
+	// ReasonOrderNotAccepted is returned when a courier picks up or delivers
+	// an order they have not accepted
+	ReasonOrderNotAccepted = "order_not_accepted"
+	// ReasonMerchantNotReady is returned when picking up from a merchant
+	// that has not marked its part ready
+	ReasonMerchantNotReady = "merchant_not_ready"
+	// ReasonPickupOutOfOrder is returned when an earlier stop of the route
+	// has not been picked up yet
+	ReasonPickupOutOfOrder = "pickup_out_of_order"
+	// ReasonInsufficientBalance is returned when the wallet balance does not
+	// cover the order total
+	ReasonInsufficientBalance = "insufficient_balance"
+)
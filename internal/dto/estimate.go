@@ -1,13 +1,18 @@
 package dto
 
+import "time"
+
 type EstimateLocation struct {
 	Lat  float64 `json:"lat" binding:"required"`
 	Long float64 `json:"long" binding:"required"`
 }
 
+// EstimateItem is an item in the cart. Options holds the IDs of the options
+// chosen for it, which apply to every unit.
 type EstimateItem struct {
-	ItemId   string `json:"itemId" binding:"required"`
-	Quantity int    `json:"quantity" binding:"required,min=1"`
+	ItemId   string   `json:"itemId" binding:"required"`
+	Quantity int      `json:"quantity" binding:"required,min=1"`
+	Options  []string `json:"options,omitempty"`
 }
 
 type EstimateOrder struct {
@@ -19,10 +24,63 @@ type EstimateOrder struct {
 type EstimateRequest struct {
 	UserLocation EstimateLocation `json:"userLocation" binding:"required"`
 	Orders       []EstimateOrder  `json:"orders" binding:"required,dive"`
+	// ScheduledAt asks for a delivery run starting at a future time. Closed
+	// merchants are then waited for instead of refused.
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"`
+}
+
+// EstimateRouteLeg is one hop of the planned route. To is a merchant ID, or
+// "userLocation" for the final drop-off leg.
+type EstimateRouteLeg struct {
+	From         string  `json:"from"`
+	To           string  `json:"to"`
+	DistanceInKm float64 `json:"distanceInKm"`
+}
+
+type EstimateRoute struct {
+	VisitOrder        []string           `json:"visitOrder"`
+	Legs              []EstimateRouteLeg `json:"legs"`
+	TotalDistanceInKm float64            `json:"totalDistanceInKm"`
+}
+
+// EstimateTimelineStop is one merchant on the route. Times are minutes since
+// the order was placed, or since StartsAt for a scheduled delivery.
+type EstimateTimelineStop struct {
+	MerchantID         string  `json:"merchantId"`
+	Subtotal           int     `json:"subtotal"`
+	ArrivalInMinutes   float64 `json:"arrivalInMinutes"`
+	PrepTimeInMinutes  float64 `json:"prepTimeInMinutes"`
+	DepartureInMinutes float64 `json:"departureInMinutes"`
+}
+
+type EstimateTimeline struct {
+	StartsAt         string                 `json:"startsAt,omitempty"`
+	Stops            []EstimateTimelineStop `json:"stops"`
+	DropOffInMinutes float64                `json:"dropOffInMinutes"`
+}
+
+// EstimateFee is one fee line on top of the item subtotal.
+type EstimateFee struct {
+	Type   string `json:"type"`
+	Amount int    `json:"amount"`
 }
 
 type EstimateResponse struct {
-	TotalPrice                  float64 `json:"totalPrice"`
-	EstimatedDeliveryTimeInMins float64 `json:"estimatedDeliveryTimeInMinutes"`
-	CalculatedEstimateID        string  `json:"calculatedEstimateId"`
+	Subtotal                    int              `json:"subtotal"`
+	Fees                        []EstimateFee    `json:"fees"`
+	TotalPrice                  float64          `json:"totalPrice"`
+	EstimatedDeliveryTimeInMins float64          `json:"estimatedDeliveryTimeInMinutes"`
+	CalculatedEstimateID        string           `json:"calculatedEstimateId"`
+	Route                       EstimateRoute    `json:"route"`
+	Timeline                    EstimateTimeline `json:"timeline"`
+}
+
+// EstimateData is stored in calculated_estimates.estimate_data. The request is
+// embedded so rows that only hold the original request still decode.
+type EstimateData struct {
+	EstimateRequest
+	Route    *EstimateRoute    `json:"route,omitempty"`
+	Timeline *EstimateTimeline `json:"timeline,omitempty"`
+	Subtotal *int              `json:"subtotal,omitempty"`
+	Fees     []EstimateFee     `json:"fees,omitempty"`
 }
@@ -0,0 +1,44 @@
+package dto
+
+// ItemOption is one choice in an option group. PriceDelta is added to the
+// item price for every unit ordered.
+type ItemOption struct {
+	OptionID   string `json:"optionId"`
+	Name       string `json:"name"`
+	PriceDelta int    `json:"priceDelta"`
+}
+
+// ItemOptionGroup is a set of options such as size or toppings. Required
+// groups have a MinSelections of at least 1.
+type ItemOptionGroup struct {
+	GroupID       string       `json:"groupId"`
+	Name          string       `json:"name"`
+	Required      bool         `json:"required"`
+	MinSelections int          `json:"minSelections"`
+	MaxSelections int          `json:"maxSelections"`
+	Options       []ItemOption `json:"options"`
+}
+
+type ItemOptionRequest struct {
+	Name       string `json:"name" binding:"required,min=1,max=30"`
+	PriceDelta int    `json:"priceDelta" binding:"min=0"`
+}
+
+type ItemOptionGroupRequest struct {
+	Name          string              `json:"name" binding:"required,min=1,max=30"`
+	MinSelections int                 `json:"minSelections" binding:"min=0"`
+	MaxSelections int                 `json:"maxSelections" binding:"required,min=1,gtefield=MinSelections"`
+	Options       []ItemOptionRequest `json:"options" binding:"required,min=1,dive"`
+}
+
+// ItemOptionGroupsRequest for PUT /admin/merchants/:merchantId/items/:itemId/options.
+// The groups replace the item's current ones, an empty list removes them.
+type ItemOptionGroupsRequest struct {
+	OptionGroups []ItemOptionGroupRequest `json:"optionGroups" binding:"dive"`
+}
+
+// ItemOptionGroupsResponse for PUT /admin/merchants/:merchantId/items/:itemId/options
+type ItemOptionGroupsResponse struct {
+	ItemID       string            `json:"itemId"`
+	OptionGroups []ItemOptionGroup `json:"optionGroups"`
+}
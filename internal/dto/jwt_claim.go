@@ -9,6 +9,15 @@ type JWTClaim struct {
 	jwt.RegisteredClaims
 }
 
+// StreamTicketClaim is a short-lived ticket that opens the event streams of
+// one order.
+type StreamTicketClaim struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	OrderID  string `json:"orderId"`
+	jwt.RegisteredClaims
+}
+
 type AuthUser struct {
 	Username string
 	Email    string
@@ -31,19 +31,38 @@ type MerchantCreateRequest struct {
 	MerchantCategory MerchantCategory `json:"merchantCategory" binding:"required"`
 	ImageURL         string           `json:"imageURL" binding:"required"`
 	Location         Location         `json:"location" binding:"required"`
+	DeliveryRadiusM  *int             `json:"deliveryRadiusM" binding:"omitempty,min=1"`
 }
 
 type MerchantCreateResponse struct {
 	MerchantId string `json:"merchantId"`
 }
 
+// MerchantUpdateRequest for PATCH /admin/merchants/:merchantId. Fields left
+// out keep their value.
+type MerchantUpdateRequest struct {
+	Name             *string           `json:"name" binding:"omitempty,min=2,max=30"`
+	MerchantCategory *MerchantCategory `json:"merchantCategory"`
+	ImageURL         *string           `json:"imageURL"`
+	Location         *Location         `json:"location"`
+	DeliveryRadiusM  *int              `json:"deliveryRadiusM" binding:"omitempty,min=1"`
+}
+
 type MerchantData struct {
 	MerchantID       string   `json:"merchantId"`
 	Name             string   `json:"name"`
 	MerchantCategory string   `json:"merchantCategory"`
 	ImageURL         string   `json:"imageUrl"`
 	Location         Location `json:"location"`
-	CreatedAt        string   `json:"createdAt"`
+	DeliveryRadiusM  int      `json:"deliveryRadiusM,omitempty"`
+	// IsOpen and OpensAt are only set by the nearby listing. OpensAt is the
+	// next opening in the merchant's time zone when it is closed.
+	IsOpen    *bool           `json:"isOpen,omitempty"`
+	OpensAt   *string         `json:"opensAt,omitempty"`
+	Rating    *MerchantRating `json:"rating,omitempty"`
+	CreatedAt string          `json:"createdAt"`
+	// DeletedAt is only set when admins list deleted merchants
+	DeletedAt *string `json:"deletedAt,omitempty"`
 }
 
 type MerchantMeta struct {
@@ -61,11 +80,11 @@ type GetMerchantsResponse struct {
 type ProductCategory string
 
 const (
-	Beverage  ProductCategory = "Beverage"
-	Food      ProductCategory = "Food"
-	Snack     ProductCategory = "Snack"
+	Beverage   ProductCategory = "Beverage"
+	Food       ProductCategory = "Food"
+	Snack      ProductCategory = "Snack"
 	Condiments ProductCategory = "Condiments"
-	Additions ProductCategory = "Additions"
+	Additions  ProductCategory = "Additions"
 )
 
 // MerchantItemCreateRequest for POST /admin/merchants/:merchantId/items
@@ -81,14 +100,44 @@ type MerchantItemCreateResponse struct {
 	ItemId string `json:"itemId"`
 }
 
-// MerchantItemData for GET /admin/merchants/:merchantId/items response
+// MerchantItemUpdateRequest for PATCH /admin/merchants/:merchantId/items/:itemId.
+// Fields left out keep their value.
+type MerchantItemUpdateRequest struct {
+	Name            *string          `json:"name" binding:"omitempty,min=2,max=30"`
+	ProductCategory *ProductCategory `json:"productCategory"`
+	Price           *int             `json:"price" binding:"omitempty,min=1"`
+	ImageURL        *string          `json:"imageUrl"`
+}
+
+// MerchantItemData for GET /admin/merchants/:merchantId/items response.
+// Available is false when the item is switched off or sold out. Stock is only
+// shown to admins and only when it is tracked.
 type MerchantItemData struct {
 	ItemId          string `json:"itemId"`
 	Name            string `json:"name"`
 	ProductCategory string `json:"productCategory"`
 	Price           int    `json:"price"`
 	ImageURL        string `json:"imageUrl"`
+	Available       bool   `json:"available"`
+	Stock           *int   `json:"stock,omitempty"`
 	CreatedAt       string `json:"createdAt"`
+	// DeletedAt is only set when admins list deleted items
+	DeletedAt    *string           `json:"deletedAt,omitempty"`
+	OptionGroups []ItemOptionGroup `json:"optionGroups,omitempty"`
+}
+
+// MerchantItemAvailabilityRequest for PUT /admin/merchants/:merchantId/items/:itemId/availability.
+// Leaving stock out or null stops tracking it.
+type MerchantItemAvailabilityRequest struct {
+	Available *bool `json:"available" binding:"required"`
+	Stock     *int  `json:"stock" binding:"omitempty,min=0"`
+}
+
+// MerchantItemAvailabilityResponse for PUT /admin/merchants/:merchantId/items/:itemId/availability
+type MerchantItemAvailabilityResponse struct {
+	ItemId    string `json:"itemId"`
+	Available bool   `json:"available"`
+	Stock     *int   `json:"stock"`
 }
 
 // GetMerchantItemsResponse for GET /admin/merchants/:merchantId/items
@@ -0,0 +1,40 @@
+package dto
+
+// OpeningHoursWindow is one weekly opening period. Day is a lowercase English
+// weekday and times are "HH:MM" in the merchant's time zone. A window that
+// closes at or before it opens runs past midnight.
+type OpeningHoursWindow struct {
+	Day      string `json:"day" binding:"required"`
+	OpensAt  string `json:"opensAt" binding:"required"`
+	ClosesAt string `json:"closesAt" binding:"required"`
+}
+
+// OpeningHoursRequest for PUT /admin/merchants/:merchantId/opening-hours. It
+// replaces the whole schedule; no windows means open all week.
+type OpeningHoursRequest struct {
+	TimeZone string               `json:"timeZone" binding:"required"`
+	Hours    []OpeningHoursWindow `json:"hours" binding:"dive"`
+}
+
+// OpeningHoursResponse for GET and PUT /admin/merchants/:merchantId/opening-hours
+type OpeningHoursResponse struct {
+	TimeZone string               `json:"timeZone"`
+	Hours    []OpeningHoursWindow `json:"hours"`
+}
+
+// MerchantHolidayRequest for PUT /admin/merchants/:merchantId/holidays/:date
+type MerchantHolidayRequest struct {
+	Note string `json:"note" binding:"max=100"`
+}
+
+// MerchantHolidayData is a local date, formatted "YYYY-MM-DD", on which the
+// merchant is closed.
+type MerchantHolidayData struct {
+	Date string `json:"date"`
+	Note string `json:"note,omitempty"`
+}
+
+// GetMerchantHolidaysResponse for GET /admin/merchants/:merchantId/holidays
+type GetMerchantHolidaysResponse struct {
+	Data []MerchantHolidayData `json:"data"`
+}
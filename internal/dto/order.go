@@ -3,11 +3,36 @@ package dto
 // Request DTOs
 type CreateOrderRequest struct {
 	CalculatedEstimateID string `json:"calculatedEstimateId" binding:"required,uuid"`
+	// PaymentMethod defaults to the wallet. Card and e-wallet payments go
+	// through the payment provider and leave the order pending_payment.
+	PaymentMethod string `json:"paymentMethod" binding:"omitempty,oneof=wallet card ewallet"`
 }
 
+// Payment methods
+const (
+	PaymentMethodWallet  = "wallet"
+	PaymentMethodCard    = "card"
+	PaymentMethodEWallet = "ewallet"
+)
+
 // Response DTOs
 type CreateOrderResponse struct {
-	OrderID string `json:"orderId"`
+	OrderID string        `json:"orderId"`
+	Payment *OrderPayment `json:"payment,omitempty"`
+}
+
+// PriceChangedResponse is returned when an estimate no longer matches current
+// prices. Data holds the re-priced totals.
+type PriceChangedResponse struct {
+	ErrorResponse
+	Data PriceChangedData `json:"data"`
+}
+
+type PriceChangedData struct {
+	PreviousTotalPrice float64       `json:"previousTotalPrice"`
+	Subtotal           int           `json:"subtotal"`
+	Fees               []EstimateFee `json:"fees"`
+	TotalPrice         float64       `json:"totalPrice"`
 }
 
 // Order history DTOs
@@ -33,6 +58,17 @@ type OrderItem struct {
 	Quantity        int    `json:"quantity"`
 	ImageURL        string `json:"imageUrl"`
 	CreatedAt       string `json:"createdAt"`
+	// Options are the choices made for the item, each adding its price delta
+	// to Price
+	Options []OrderItemOption `json:"options,omitempty"`
+}
+
+// OrderItemOption is an option as it was when the order was placed
+type OrderItemOption struct {
+	OptionID   string `json:"optionId"`
+	GroupName  string `json:"groupName"`
+	Name       string `json:"name"`
+	PriceDelta int    `json:"priceDelta"`
 }
 
 type OrderDetail struct {
@@ -41,8 +77,82 @@ type OrderDetail struct {
 }
 
 type OrderHistory struct {
-	OrderID string        `json:"orderId"`
-	Orders  []OrderDetail `json:"orders"`
+	OrderID  string            `json:"orderId"`
+	Status   string            `json:"status"`
+	Orders   []OrderDetail     `json:"orders"`
+	Timeline *EstimateTimeline `json:"timeline,omitempty"`
+}
+
+// OrderStatusEventData is the data of a status event. From is null for the
+// order being placed.
+type OrderStatusEventData struct {
+	From   *string `json:"from"`
+	Status string  `json:"status"`
+}
+
+// OrderEventsTicketResponse carries the ticket a browser passes as ?ticket=
+// to open an order's event stream.
+type OrderEventsTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// UpdateOrderStatusRequest for PATCH /admin/orders/:orderId/status
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+type UpdateOrderStatusResponse struct {
+	OrderID string `json:"orderId"`
+	Status  string `json:"status"`
+}
+
+// CancelOrderRequest for POST /users/orders/:orderId/cancel
+type CancelOrderRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// CancelOrderResponse holds the fee kept under the cancellation policy and
+// the amount refunded to the user.
+type CancelOrderResponse struct {
+	OrderID      string `json:"orderId"`
+	Status       string `json:"status"`
+	Fee          int    `json:"fee"`
+	RefundAmount int    `json:"refundAmount"`
+}
+
+// MerchantOrderItem is an item of one merchant's part of an order, named as
+// it was when the order was placed
+type MerchantOrderItem struct {
+	ItemID   string            `json:"itemId"`
+	Name     string            `json:"name"`
+	Quantity int               `json:"quantity"`
+	Options  []OrderItemOption `json:"options,omitempty"`
+}
+
+// MerchantOrderData for GET /admin/merchants/:merchantId/orders. Status is the
+// merchant's sub-status and Position its place on the courier route.
+type MerchantOrderData struct {
+	OrderID     string              `json:"orderId"`
+	OrderStatus string              `json:"orderStatus"`
+	Status      string              `json:"status"`
+	Position    int                 `json:"position"`
+	Subtotal    int                 `json:"subtotal"`
+	Items       []MerchantOrderItem `json:"items"`
+	CreatedAt   string              `json:"createdAt"`
+}
+
+type GetMerchantOrdersResponse struct {
+	Data []MerchantOrderData `json:"data"`
+	Meta MerchantMeta        `json:"meta"`
+}
+
+// MerchantOrderStatusResponse for the accept, reject and ready actions
+type MerchantOrderStatusResponse struct {
+	OrderID     string `json:"orderId"`
+	MerchantID  string `json:"merchantId"`
+	Status      string `json:"status"`
+	OrderStatus string `json:"orderStatus"`
 }
 
 // Response with meta pagination info
@@ -68,4 +178,8 @@ type GetOrdersParams struct {
 	Name             *string `form:"name"`
 	MerchantCategory *string `form:"merchantCategory"`
 	CreatedAt        *string `form:"createdAt"`
+	From             *string `form:"from"`
+	To               *string `form:"to"`
+	// Meta opts into the GetOrdersResponse envelope instead of a bare array
+	Meta bool `form:"meta"`
 }
@@ -0,0 +1,17 @@
+package dto
+
+// OrderPayment is a payment made through the payment provider. The client
+// completes it with the provider using ClientSecret. Both are empty until
+// the provider intent was created.
+type OrderPayment struct {
+	Provider     string `json:"provider"`
+	Method       string `json:"method"`
+	IntentID     string `json:"intentId,omitempty"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+	Amount       int    `json:"amount"`
+	Status       string `json:"status"`
+}
+
+type PaymentWebhookResponse struct {
+	Received bool `json:"received"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+// ReorderItem is an item of the past order that was left out of the new
+// estimate.
+type ReorderItem struct {
+	MerchantID string `json:"merchantId"`
+	ItemID     string `json:"itemId"`
+	Name       string `json:"name"`
+	Quantity   int    `json:"quantity"`
+}
+
+// RepricedItem is an item whose price changed since the past order.
+type RepricedItem struct {
+	MerchantID    string `json:"merchantId"`
+	ItemID        string `json:"itemId"`
+	Name          string `json:"name"`
+	PreviousPrice int    `json:"previousPrice"`
+	Price         int    `json:"price"`
+}
+
+// ReorderDiff compares a past order with the current catalog. Gone items no
+// longer exist, unavailable ones are switched off, out of stock or their
+// chosen options can no longer be ordered.
+type ReorderDiff struct {
+	Gone        []ReorderItem  `json:"gone"`
+	Unavailable []ReorderItem  `json:"unavailable"`
+	Repriced    []RepricedItem `json:"repriced"`
+}
+
+// ReorderResponse for POST /users/orders/:orderId/reorder
+type ReorderResponse struct {
+	EstimateResponse
+	Diff ReorderDiff `json:"diff"`
+}
+
+// NothingToReorderResponse is returned when no item of the past order can be
+// ordered again.
+type NothingToReorderResponse struct {
+	ErrorResponse
+	Data ReorderDiff `json:"data"`
+}
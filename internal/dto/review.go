@@ -0,0 +1,37 @@
+package dto
+
+// CreateReviewRequest for POST /users/orders/:orderId/reviews. ImageURL is
+// optional and comes from the image upload endpoint.
+type CreateReviewRequest struct {
+	MerchantID string `json:"merchantId" binding:"required,uuid"`
+	Rating     int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment    string `json:"comment" binding:"max=1000"`
+	ImageURL   string `json:"imageUrl"`
+}
+
+type CreateReviewResponse struct {
+	OrderID    string `json:"orderId"`
+	MerchantID string `json:"merchantId"`
+	Rating     int    `json:"rating"`
+}
+
+type ReviewData struct {
+	OrderID   string `json:"orderId"`
+	Username  string `json:"username"`
+	Rating    int    `json:"rating"`
+	Comment   string `json:"comment,omitempty"`
+	ImageURL  string `json:"imageUrl,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type GetMerchantReviewsResponse struct {
+	Data []ReviewData `json:"data"`
+	Meta MerchantMeta `json:"meta"`
+}
+
+// MerchantRating is a merchant's average review score. Average is 0 until
+// the first review.
+type MerchantRating struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+// WalletTopUpRequest for POST /admin/wallets/top-ups. Amount is credited to
+// the user's wallet.
+type WalletTopUpRequest struct {
+	Username string `json:"username" binding:"required"`
+	Amount   int64  `json:"amount" binding:"required,min=1"`
+}
+
+type WalletTopUpResponse struct {
+	TransactionID string `json:"transactionId"`
+	Username      string `json:"username"`
+	Amount        int64  `json:"amount"`
+	Balance       int64  `json:"balance"`
+}
+
+// WalletEntry is one movement of a wallet, negative amounts are debits
+type WalletEntry struct {
+	TransactionID string  `json:"transactionId"`
+	Kind          string  `json:"kind"`
+	OrderID       *string `json:"orderId"`
+	Amount        int64   `json:"amount"`
+	CreatedAt     string  `json:"createdAt"`
+}
+
+// GetWalletResponse for GET /users/wallet. Data holds the latest entries
+// first.
+type GetWalletResponse struct {
+	Balance int64         `json:"balance"`
+	Data    []WalletEntry `json:"data"`
+	Meta    MerchantMeta  `json:"meta"`
+}
+
+// InsufficientBalanceResponse is returned when the wallet cannot pay for an
+// order
+type InsufficientBalanceResponse struct {
+	ErrorResponse
+	Data InsufficientBalanceData `json:"data"`
+}
+
+type InsufficientBalanceData struct {
+	Balance  int64 `json:"balance"`
+	Required int64 `json:"required"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "encoding/json"
+
+type DeliveryZoneCreateRequest struct {
+	Name            string     `json:"name" binding:"required,min=2,max=50"`
+	DeliveryRadiusM int        `json:"deliveryRadiusM" binding:"required,min=1"`
+	Area            []Location `json:"area" binding:"required,min=3"`
+}
+
+type DeliveryZoneCreateResponse struct {
+	ZoneId string `json:"zoneId"`
+}
+
+type DeliveryZoneData struct {
+	ZoneID          string `json:"zoneId"`
+	Name            string `json:"name"`
+	DeliveryRadiusM int    `json:"deliveryRadiusM"`
+	// Area is the zone polygon as a GeoJSON geometry
+	Area      json.RawMessage `json:"area"`
+	CreatedAt string          `json:"createdAt"`
+}
+
+type GetDeliveryZonesResponse struct {
+	Data []DeliveryZoneData `json:"data"`
+}
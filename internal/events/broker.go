@@ -0,0 +1,155 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Channel is the Postgres notification channel the order_events trigger
+// publishes on.
+const Channel = "order_events"
+
+// Event types
+const (
+	TypeStatus          = "status"
+	TypeCourierPosition = "courier_position"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind by before
+// it is dropped.
+const subscriberBuffer = 16
+
+// reconnectDelay is the wait before listening again after the connection failed.
+const reconnectDelay = time.Second
+
+// Event is an order event as the order_events trigger publishes it.
+type Event struct {
+	ID        int64           `json:"id"`
+	OrderID   string          `json:"orderId"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Broker fans order events received through LISTEN out to the subscribers
+// of each order. Every replica runs its own broker, so an event reaches all
+// clients whichever replica they are connected to.
+type Broker struct {
+	pool *pgxpool.Pool
+
+	mu   sync.Mutex
+	subs map[string]map[chan Event]struct{}
+}
+
+func NewBroker(pool *pgxpool.Pool) *Broker {
+	return &Broker{pool: pool, subs: make(map[string]map[chan Event]struct{})}
+}
+
+// Subscribe returns the events of an order published from now on. The
+// channel is closed when the subscriber falls behind or the broker lost
+// events while reconnecting; the client is expected to resume from the last
+// event it saw. cancel must be called once the subscriber is done.
+func (b *Broker) Subscribe(orderID string) (events <-chan Event, cancel func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[orderID] == nil {
+		b.subs[orderID] = make(map[chan Event]struct{})
+	}
+	b.subs[orderID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(orderID, ch)
+	}
+}
+
+// remove closes ch unless it was removed already. b.mu must be held.
+func (b *Broker) remove(orderID string, ch chan Event) {
+	if _, ok := b.subs[orderID][ch]; !ok {
+		return
+	}
+	delete(b.subs[orderID], ch)
+	if len(b.subs[orderID]) == 0 {
+		delete(b.subs, orderID)
+	}
+	close(ch)
+}
+
+func (b *Broker) publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[e.OrderID] {
+		select {
+		case ch <- e:
+		default:
+			b.remove(e.OrderID, ch)
+		}
+	}
+}
+
+// dropAll closes every subscription, used when notifications may have been
+// missed.
+func (b *Broker) dropAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for orderID, chans := range b.subs {
+		for ch := range chans {
+			b.remove(orderID, ch)
+		}
+	}
+}
+
+// Run listens for events until ctx is done, reconnecting when the
+// connection fails.
+func (b *Broker) Run(ctx context.Context) {
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error().Err(err).Msg("order events listener stopped, reconnecting")
+		b.dropAll()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (b *Broker) listen(ctx context.Context) error {
+	pooled, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// A listening connection must not go back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var e Event
+		if err := json.Unmarshal([]byte(notification.Payload), &e); err != nil {
+			log.Error().Err(err).Msg("invalid order event payload")
+			continue
+		}
+		b.publish(e)
+	}
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/middleware"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CourierHandler wires courier endpoints to sqlc-generated queries.
+type CourierHandler struct {
+	Q    *db.Queries
+	pool *pgxpool.Pool
+}
+
+func NewCourierHandler(pool *pgxpool.Pool) *CourierHandler {
+	q := db.New(pool)
+	return &CourierHandler{Q: q, pool: pool}
+}
+
+func (h *CourierHandler) RegisterCourier(c *gin.Context) {
+	var payload dto.UserRegisterRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: please make sure you have provided a valid username, email, and password",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	hashedPassword, err := shared.HashPassword(payload.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to hash password",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// Couriers start unavailable until they report a location
+	err = h.Q.CreateCourier(c, db.CreateCourierParams{
+		Username: payload.Username,
+		Password: hashedPassword,
+		Email:    payload.Email,
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	token, err := middleware.GenerateToken(dto.AuthUser{
+		Username: payload.Username,
+		Email:    payload.Email,
+		Role:     string(db.UserRoleCourier),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate authentication token",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.UserAuthResponse{
+		Token: token,
+	})
+}
+
+func (h *CourierHandler) LoginCourier(c *gin.Context) {
+	var payload dto.UserLoginRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: please make sure you have provided a valid username and password",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	courier, err := h.Q.GetCourierByUsername(c, payload.Username)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid username or password",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := shared.VerifyPassword(payload.Password, courier.Password); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid username or password",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	token, err := middleware.GenerateToken(dto.AuthUser{
+		Username: courier.Username,
+		Email:    courier.Email,
+		Role:     string(db.UserRoleCourier),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate token",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.UserAuthResponse{
+		Token: token,
+	})
+}
+
+// UpdateAvailability starts or stops a courier taking orders. A courier
+// becoming available is assigned the nearest order waiting for one.
+func (h *CourierHandler) UpdateAvailability(c *gin.Context) {
+	var req dto.CourierAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body: available is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if *req.Available && req.Location == nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Location is required to become available",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var lat, long pgtype.Float8
+	if req.Location != nil {
+		if req.Location.Lat < -90 || req.Location.Lat > 90 || req.Location.Long < -180 || req.Location.Long > 180 {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   "Invalid location. Latitude must be between -90 and 90 and longitude between -180 and 180",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		lat = pgtype.Float8{Float64: req.Location.Lat, Valid: true}
+		long = pgtype.Float8{Float64: req.Location.Long, Valid: true}
+	}
+
+	courier, ok := h.authCourier(c)
+	if !ok {
+		return
+	}
+
+	tx, err := h.pool.Begin(c)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	defer tx.Rollback(c)
+	qtx := h.Q.WithTx(tx)
+
+	err = qtx.SetCourierAvailability(c, db.SetCourierAvailabilityParams{
+		Available: *req.Available,
+		Long:      long,
+		Lat:       lat,
+		UserID:    courier.ID,
+	})
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if *req.Available {
+		if err := qtx.AssignNearestOrder(c, courier.ID); err != nil {
+			writeOrderStatusError(c, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(c); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CourierAvailabilityResponse{
+		Available: *req.Available,
+	})
+}
+
+// authCourier loads the courier behind the token. It writes the error
+// response when ok is false.
+func (h *CourierHandler) authCourier(c *gin.Context) (courier db.User, ok bool) {
+	username, _ := c.Get("username")
+	courier, err := h.Q.GetCourierByUsername(c, username.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "Courier not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return courier, false
+	}
+	return courier, true
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/events"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// courierSpeedWindow is how far back pings are used to measure a courier's speed
+const courierSpeedWindow = 10 * time.Minute
+
+// minSpeedSampleSpan is the shortest span of pings a speed is measured over
+const minSpeedSampleSpan = time.Minute
+
+// observedSpeedKmh adjusts courierSpeedKmh to how fast the courier actually
+// moved between pings. It stays between a quarter and double the planned
+// speed so waiting at a merchant or a noisy fix does not throw the estimate
+// off.
+func observedSpeedKmh(pings []db.GetCourierLocationsSinceRow) float64 {
+	if len(pings) < 2 {
+		return courierSpeedKmh
+	}
+	span := pings[len(pings)-1].RecordedAt.Time.Sub(pings[0].RecordedAt.Time)
+	if span < minSpeedSampleSpan {
+		return courierSpeedKmh
+	}
+
+	distance := 0.0
+	for i := 1; i < len(pings); i++ {
+		distance += shared.Haversine(pings[i-1].Lat, pings[i-1].Long, pings[i].Lat, pings[i].Long)
+	}
+	speed := distance / span.Hours()
+	return math.Min(math.Max(speed, courierSpeedKmh/4), courierSpeedKmh*2)
+}
+
+// remainingRouteKm is the distance from the courier through the stops not
+// picked up yet, in route order, to the user.
+func remainingRouteKm(from dto.OrderLocation, stops []db.GetOrderPickupsRow, to dto.OrderLocation) float64 {
+	distance := 0.0
+	for _, stop := range stops {
+		if stop.PickedUpAt.Valid {
+			continue
+		}
+		distance += shared.Haversine(from.Lat, from.Long, stop.Lat, stop.Long)
+		from = dto.OrderLocation{Lat: stop.Lat, Long: stop.Long}
+	}
+	return distance + shared.Haversine(from.Lat, from.Long, to.Lat, to.Long)
+}
+
+// RecordLocation stores a GPS ping and publishes it to users watching the
+// courier's orders.
+func (h *CourierHandler) RecordLocation(c *gin.Context) {
+	var req dto.CourierLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body: lat must be between -90 and 90 and long between -180 and 180",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	courier, ok := h.authCourier(c)
+	if !ok {
+		return
+	}
+
+	tx, err := h.pool.Begin(c)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	defer tx.Rollback(c)
+	qtx := h.Q.WithTx(tx)
+
+	recordedAt, err := qtx.RecordCourierLocation(c, db.RecordCourierLocationParams{
+		CourierID: courier.ID,
+		Long:      *req.Long,
+		Lat:       *req.Lat,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Courier profile not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	orders, err := qtx.GetCourierOrders(c, courier.ID)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	data := dto.CourierPositionEventData{
+		Lat:        *req.Lat,
+		Long:       *req.Long,
+		RecordedAt: recordedAt.Time.Format(shared.ISO8601WithNanoseconds),
+	}
+	for _, order := range orders {
+		if err := createOrderEvent(c, qtx, order.ID, events.TypeCourierPosition, data); err != nil {
+			writeOrderStatusError(c, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(c); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.CourierLocationResponse{
+		RecordedAt: data.RecordedAt,
+	})
+}
+
+// GetCourierPosition returns where the courier of the user's order is and
+// how long the rest of the route should take. The courier is no longer shown
+// once the order is delivered, cancelled or rejected.
+func (h *OrderHandler) GetCourierPosition(c *gin.Context) {
+	username, _ := c.Get("username")
+	user, err := h.Q.GetUserByUsername(c, username.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var orderID pgtype.UUID
+	if err := orderID.Scan(c.Param("orderId")); err != nil {
+		writeOrderStatusError(c, errOrderNotFound)
+		return
+	}
+
+	// Other users' orders read as not found
+	status, err := h.Q.GetUserOrderStatus(c, db.GetUserOrderStatusParams{
+		ID:     orderID,
+		UserID: user.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeOrderStatusError(c, errOrderNotFound)
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if isFinalOrderStatus(status) {
+		c.JSON(http.StatusGone, dto.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("The order is %s, its courier is no longer tracked", status),
+			Code:    http.StatusGone,
+		})
+		return
+	}
+
+	position, err := h.Q.GetOrderCourierPosition(c, orderID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "No courier position is available for this order yet",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	pings, err := h.Q.GetCourierLocationsSince(c, db.GetCourierLocationsSinceParams{
+		CourierID:  position.CourierID,
+		RecordedAt: pgtype.Timestamptz{Time: time.Now().Add(-courierSpeedWindow), Valid: true},
+	})
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	speed := observedSpeedKmh(pings)
+
+	stops, err := h.Q.GetOrderPickups(c, []pgtype.UUID{orderID})
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	courierLocation := dto.OrderLocation{Lat: position.Lat, Long: position.Long}
+	distance := remainingRouteKm(courierLocation, stops, dto.OrderLocation{Lat: position.UserLat, Long: position.UserLong})
+
+	c.JSON(http.StatusOK, dto.CourierPositionResponse{
+		OrderID:             orderID.String(),
+		Location:            courierLocation,
+		UpdatedAt:           position.LocationUpdatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+		SpeedKmh:            math.Round(speed*10) / 10,
+		RemainingDistanceKm: math.Round(distance*100) / 100,
+		RemainingMinutes:    int(math.Ceil(distance / speed * 60)),
+	})
+}
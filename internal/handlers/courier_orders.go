@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// GetCourierOrders lists the orders assigned to the courier that are not
+// finished yet, with their stops in route order.
+func (h *CourierHandler) GetCourierOrders(c *gin.Context) {
+	courier, ok := h.authCourier(c)
+	if !ok {
+		return
+	}
+
+	orders, err := h.Q.GetCourierOrders(c, courier.ID)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	orderIDs := make([]pgtype.UUID, 0, len(orders))
+	for _, order := range orders {
+		orderIDs = append(orderIDs, order.ID)
+	}
+	pickups, err := h.Q.GetOrderPickups(c, orderIDs)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	stops := make(map[pgtype.UUID][]dto.CourierStop, len(orders))
+	for _, p := range pickups {
+		stops[p.OrderID] = append(stops[p.OrderID], dto.CourierStop{
+			MerchantID: p.MerchantID.String(),
+			Name:       p.Name,
+			Location:   dto.OrderLocation{Lat: p.Lat, Long: p.Long},
+			Status:     string(p.Status),
+			PickedUpAt: formatOptionalTime(p.PickedUpAt),
+		})
+	}
+
+	data := make([]dto.CourierOrder, 0, len(orders))
+	for _, order := range orders {
+		data = append(data, dto.CourierOrder{
+			OrderID:      order.ID.String(),
+			Status:       string(order.Status),
+			AcceptedAt:   formatOptionalTime(order.CourierAcceptedAt),
+			Stops:        stops[order.ID],
+			UserLocation: dto.OrderLocation{Lat: order.UserLat, Long: order.UserLong},
+			CreatedAt:    order.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+		})
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+func formatOptionalTime(t pgtype.Timestamptz) *string {
+	if !t.Valid {
+		return nil
+	}
+	formatted := t.Time.Format(shared.ISO8601WithNanoseconds)
+	return &formatted
+}
+
+// AcceptCourierOrder confirms the courier takes the order assigned to them.
+// Accepting twice is harmless.
+func (h *CourierHandler) AcceptCourierOrder(c *gin.Context) {
+	h.updateCourierOrder(c, func(q *db.Queries, courier db.User, orderID pgtype.UUID, order db.GetCourierOrderForUpdateRow) (db.OrderStatus, bool) {
+		if !order.CourierAcceptedAt.Valid {
+			if err := q.AcceptCourierOrder(c, orderID); err != nil {
+				writeOrderStatusError(c, err)
+				return "", false
+			}
+		}
+		return order.Status, true
+	})
+}
+
+// PickUpOrderMerchant confirms the courier collected a merchant's part of the
+// order. Stops are picked up in route order once the merchant marked them
+// ready, the order is picked up with the last one.
+func (h *CourierHandler) PickUpOrderMerchant(c *gin.Context) {
+	var merchantID pgtype.UUID
+	if err := merchantID.Scan(c.Param("merchantId")); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid merchant ID format",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	h.updateCourierOrder(c, func(q *db.Queries, courier db.User, orderID pgtype.UUID, order db.GetCourierOrderForUpdateRow) (db.OrderStatus, bool) {
+		if !requireCourierAccepted(c, order) {
+			return "", false
+		}
+
+		pickups, err := q.GetOrderPickups(c, []pgtype.UUID{orderID})
+		if err != nil {
+			writeOrderStatusError(c, err)
+			return "", false
+		}
+
+		remaining := 0
+		var stop *db.GetOrderPickupsRow
+		for i := range pickups {
+			if pickups[i].MerchantID == merchantID {
+				stop = &pickups[i]
+			} else if !pickups[i].PickedUpAt.Valid {
+				remaining++
+			}
+		}
+		if stop == nil {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Success: false,
+				Error:   "Merchant is not part of this order",
+				Code:    http.StatusNotFound,
+			})
+			return "", false
+		}
+		// Confirming the same pickup again is harmless
+		if stop.PickedUpAt.Valid {
+			return order.Status, true
+		}
+		if stop.Status != db.OrderMerchantStatusReady {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Merchant order is %s, not ready", stop.Status),
+				Code:    http.StatusConflict,
+				Reason:  dto.ReasonMerchantNotReady,
+			})
+			return "", false
+		}
+		for _, p := range pickups {
+			if p.Position < stop.Position && !p.PickedUpAt.Valid {
+				c.JSON(http.StatusConflict, dto.ErrorResponse{
+					Success: false,
+					Error:   fmt.Sprintf("Pick up from %s first", p.Name),
+					Code:    http.StatusConflict,
+					Reason:  dto.ReasonPickupOutOfOrder,
+				})
+				return "", false
+			}
+		}
+
+		err = q.MarkOrderMerchantPickedUp(c, db.MarkOrderMerchantPickedUpParams{
+			OrderID:    orderID,
+			MerchantID: merchantID,
+		})
+		if err != nil {
+			writeOrderStatusError(c, err)
+			return "", false
+		}
+
+		if remaining > 0 {
+			return order.Status, true
+		}
+		if err := transitionOrder(c, q, orderID, db.OrderStatusPickedUp, userActor(courier)); err != nil {
+			writeOrderStatusError(c, err)
+			return "", false
+		}
+		return db.OrderStatusPickedUp, true
+	})
+}
+
+// DeliverCourierOrder confirms the courier handed the order to the user
+func (h *CourierHandler) DeliverCourierOrder(c *gin.Context) {
+	h.updateCourierOrder(c, func(q *db.Queries, courier db.User, orderID pgtype.UUID, order db.GetCourierOrderForUpdateRow) (db.OrderStatus, bool) {
+		if !requireCourierAccepted(c, order) {
+			return "", false
+		}
+		if err := transitionOrder(c, q, orderID, db.OrderStatusDelivered, userActor(courier)); err != nil {
+			writeOrderStatusError(c, err)
+			return "", false
+		}
+		return db.OrderStatusDelivered, true
+	})
+}
+
+// updateCourierOrder runs update in a transaction holding the lock on an
+// order assigned to the courier. update writes the error response and
+// returns false when the order cannot be updated, the transaction is then
+// rolled back.
+func (h *CourierHandler) updateCourierOrder(c *gin.Context, update func(q *db.Queries, courier db.User, orderID pgtype.UUID, order db.GetCourierOrderForUpdateRow) (db.OrderStatus, bool)) {
+	courier, ok := h.authCourier(c)
+	if !ok {
+		return
+	}
+
+	var orderID pgtype.UUID
+	if err := orderID.Scan(c.Param("orderId")); err != nil {
+		writeOrderStatusError(c, errOrderNotFound)
+		return
+	}
+
+	tx, err := h.pool.Begin(c)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	defer tx.Rollback(c)
+	qtx := h.Q.WithTx(tx)
+
+	// Orders assigned to other couriers read as not found
+	order, err := qtx.GetCourierOrderForUpdate(c, db.GetCourierOrderForUpdateParams{
+		ID:        orderID,
+		CourierID: courier.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeOrderStatusError(c, errOrderNotFound)
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if isFinalOrderStatus(order.Status) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Order is already %s", order.Status),
+			Code:    http.StatusConflict,
+			Reason:  dto.ReasonInvalidTransition,
+		})
+		return
+	}
+
+	status, ok := update(qtx, courier, orderID, order)
+	if !ok {
+		return
+	}
+	if err := tx.Commit(c); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CourierOrderResponse{
+		OrderID: orderID.String(),
+		Status:  string(status),
+	})
+}
+
+// requireCourierAccepted writes the error response when the courier has not
+// accepted the order yet.
+func requireCourierAccepted(c *gin.Context, order db.GetCourierOrderForUpdateRow) bool {
+	if order.CourierAcceptedAt.Valid {
+		return true
+	}
+	c.JSON(http.StatusConflict, dto.ErrorResponse{
+		Success: false,
+		Error:   "Accept the order first",
+		Code:    http.StatusConflict,
+		Reason:  dto.ReasonOrderNotAccepted,
+	})
+	return false
+}
@@ -1,26 +1,33 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"math"
 	"net/http"
-	"sync"
+	"strings"
+	"time"
 
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/config"
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type EstimateHandler struct {
-	Q *db.Queries
+	Q   *db.Queries
+	cfg *config.EstimateConfig
 }
 
-func NewEstimateHandler(pool *pgxpool.Pool) *EstimateHandler {
+func NewEstimateHandler(pool *pgxpool.Pool, cfg *config.EstimateConfig) *EstimateHandler {
 	q := db.New(pool)
-	return &EstimateHandler{Q: q}
+	return &EstimateHandler{Q: q, cfg: cfg}
 }
 
 func (h *EstimateHandler) Estimate(c *gin.Context) {
@@ -34,101 +41,14 @@ func (h *EstimateHandler) Estimate(c *gin.Context) {
 		return
 	}
 
-	startCount := 0
-	for _, o := range req.Orders {
-		if o.IsStartingPoint {
-			startCount++
-		}
-	}
-	if startCount != 1 {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Success: false,
-			Error:   "There must be exactly one starting point",
-			Code:    http.StatusBadRequest,
-		})
-		return
-	}
-
 	ctx := c.Request.Context()
-	totalPrice := 0.0
-	maxDistance := 0.0
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	errChan := make(chan error, len(req.Orders))
-	concurrency := make(chan struct{}, 4)
-
-	for _, order := range req.Orders {
-		wg.Add(1)
-		go func(order dto.EstimateOrder) {
-			defer wg.Done()
-			concurrency <- struct{}{}
-			defer func() { <-concurrency }()
-
-			merchant, err := h.Q.GetMerchantLocationByID(ctx, order.MerchantId)
-			if err != nil {
-				errChan <- err
-				return
-			}
-
-			dist := shared.Haversine(
-				req.UserLocation.Lat,
-				req.UserLocation.Long,
-				merchant.Lat,
-				merchant.Long,
-			)
-			log.Printf("Distance to merchant %s: %.2f km", order.MerchantId, dist)
 
-			if dist > 3 {
-				errChan <- &DistanceError{}
-				return
-			}
-
-			mu.Lock()
-			if dist > maxDistance {
-				maxDistance = dist
-			}
-			mu.Unlock()
-
-			for _, item := range order.Items {
-				itemData, err := h.Q.GetMerchantItemPriceByID(ctx, item.ItemId)
-				if err != nil {
-					errChan <- err
-					return
-				}
-
-				mu.Lock()
-				totalPrice += float64(itemData.Price * int32(item.Quantity))
-				mu.Unlock()
-			}
-		}(order)
-	}
-
-	wg.Wait()
-	close(errChan)
-
-	for err := range errChan {
-		switch err.(type) {
-		case *DistanceError:
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Success: false,
-				Error:   "One of the merchants is too far (over 3km)",
-				Code:    http.StatusBadRequest,
-			})
-			return
-		default:
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Success: false,
-				Error:   "One of the merchants or items not found",
-				Code:    http.StatusNotFound,
-			})
-			return
-		}
+	quote, err := quoteEstimate(ctx, h.Q, h.cfg, req, false)
+	if err != nil {
+		writeEstimateError(c, err)
+		return
 	}
 
-	const speed = 40.0
-	deliveryTime := (maxDistance / speed) * 60
-
 	// Get user from JWT
 	username, exists := c.Get("username")
 	if !exists {
@@ -151,45 +71,550 @@ func (h *EstimateHandler) Estimate(c *gin.Context) {
 		return
 	}
 
-	// Round and store values
-	roundedTotalPrice := math.Round(totalPrice*100) / 100
-	roundedDeliveryTime := math.Round(deliveryTime*100) / 100
-
-	// Save estimate data to JSON
-	rawJSON, err := json.Marshal(req)
+	estimateID, err := storeEstimate(ctx, h.Q, h.cfg, user.ID, req, quote)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Success: false,
-			Error:   "Failed to store estimate data",
+			Error:   "Failed to store estimate",
 			Code:    http.StatusInternalServerError,
 		})
 		return
 	}
-	estimateData := rawJSON
 
-	// Store the calculated estimate in the database
-	estimateID, err := h.Q.CreateCalculatedEstimate(ctx, db.CreateCalculatedEstimateParams{
-		UserID:                       user.ID,
-		TotalPrice:                   int32(roundedTotalPrice),
-		EstimatedDeliveryTimeMinutes: int32(roundedDeliveryTime),
+	c.JSON(http.StatusOK, quote.response(estimateID))
+}
+
+// storeEstimate saves a quote as a calculated estimate the user can order
+// until it expires.
+func storeEstimate(ctx context.Context, q *db.Queries, cfg *config.EstimateConfig, userID pgtype.UUID, req dto.EstimateRequest, quote estimateQuote) (pgtype.UUID, error) {
+	estimateData, err := json.Marshal(dto.EstimateData{
+		EstimateRequest: req,
+		Route:           &quote.Route,
+		Timeline:        &quote.Timeline,
+		Subtotal:        &quote.Subtotal,
+		Fees:            quote.Fees,
+	})
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	return q.CreateCalculatedEstimate(ctx, db.CreateCalculatedEstimateParams{
+		UserID:                       userID,
+		TotalPrice:                   int32(quote.TotalPrice),
+		EstimatedDeliveryTimeMinutes: int32(quote.DeliveryTime),
 		EstimateData:                 estimateData,
+		ExpiresAt:                    pgtype.Timestamptz{Time: time.Now().Add(cfg.TTL), Valid: true},
+	})
+}
+
+// estimateQuote is a priced and routed EstimateRequest.
+type estimateQuote struct {
+	Subtotal     int
+	Fees         []dto.EstimateFee
+	TotalPrice   float64
+	DeliveryTime float64
+	Route        dto.EstimateRoute
+	Timeline     dto.EstimateTimeline
+}
+
+func (quote estimateQuote) response(estimateID pgtype.UUID) dto.EstimateResponse {
+	return dto.EstimateResponse{
+		Subtotal:                    quote.Subtotal,
+		Fees:                        quote.Fees,
+		TotalPrice:                  quote.TotalPrice,
+		EstimatedDeliveryTimeInMins: quote.DeliveryTime,
+		CalculatedEstimateID:        estimateID.String(),
+		Route:                       quote.Route,
+		Timeline:                    quote.Timeline,
+	}
+}
+
+var (
+	errStartingPoint    = errors.New("there must be exactly one starting point")
+	errEstimateSettings = errors.New("failed to load estimate settings")
+	errEstimateCatalog  = errors.New("failed to load estimate catalog")
+)
+
+// estimateCatalog holds every merchant and item referenced by a request.
+// Option groups are keyed by item ID and options by their own ID.
+type estimateCatalog struct {
+	merchants    map[pgtype.UUID]db.GetEstimateMerchantsRow
+	items        map[pgtype.UUID]db.GetEstimateItemsRow
+	hours        map[pgtype.UUID]shared.OpeningHours
+	optionGroups map[pgtype.UUID][]db.ItemOptionGroup
+	options      map[pgtype.UUID]db.GetItemOptionsRow
+}
+
+// parseUUID converts a request ID. Malformed IDs give an invalid UUID, which
+// never matches a catalog entry and so reads as not found.
+func parseUUID(id string) pgtype.UUID {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}
+}
+
+// loadEstimateCatalog fetches all referenced merchants, their opening hours,
+// items and item options with one statement each, whatever the size of the
+// cart.
+func loadEstimateCatalog(ctx context.Context, q *db.Queries, req dto.EstimateRequest) (estimateCatalog, error) {
+	merchantIDs := make([]pgtype.UUID, 0, len(req.Orders))
+	itemIDs := make([]pgtype.UUID, 0, len(req.Orders))
+	for _, order := range req.Orders {
+		if id := parseUUID(order.MerchantId); id.Valid {
+			merchantIDs = append(merchantIDs, id)
+		}
+		for _, item := range order.Items {
+			if id := parseUUID(item.ItemId); id.Valid {
+				itemIDs = append(itemIDs, id)
+			}
+		}
+	}
+
+	merchants, err := q.GetEstimateMerchants(ctx, db.GetEstimateMerchantsParams{
+		UserLong: req.UserLocation.Long,
+		UserLat:  req.UserLocation.Lat,
+		Ids:      merchantIDs,
 	})
 	if err != nil {
+		return estimateCatalog{}, errEstimateCatalog
+	}
+	items, err := q.GetEstimateItems(ctx, itemIDs)
+	if err != nil {
+		return estimateCatalog{}, errEstimateCatalog
+	}
+	groups, err := q.GetItemOptionGroups(ctx, itemIDs)
+	if err != nil {
+		return estimateCatalog{}, errEstimateCatalog
+	}
+	options, err := q.GetItemOptions(ctx, itemIDs)
+	if err != nil {
+		return estimateCatalog{}, errEstimateCatalog
+	}
+
+	catalog := estimateCatalog{
+		merchants:    make(map[pgtype.UUID]db.GetEstimateMerchantsRow, len(merchants)),
+		items:        make(map[pgtype.UUID]db.GetEstimateItemsRow, len(items)),
+		optionGroups: make(map[pgtype.UUID][]db.ItemOptionGroup, len(groups)),
+		options:      make(map[pgtype.UUID]db.GetItemOptionsRow, len(options)),
+	}
+	timeZones := make(map[pgtype.UUID]string, len(merchants))
+	for _, m := range merchants {
+		catalog.merchants[m.ID] = m
+		timeZones[m.ID] = m.TimeZone
+	}
+	for _, it := range items {
+		catalog.items[it.ID] = it
+	}
+	for _, g := range groups {
+		catalog.optionGroups[g.ItemID] = append(catalog.optionGroups[g.ItemID], g)
+	}
+	for _, o := range options {
+		catalog.options[o.ID] = o
+	}
+
+	catalog.hours, err = loadOpeningHours(ctx, q, timeZones, time.Now())
+	if err != nil {
+		return estimateCatalog{}, errEstimateCatalog
+	}
+
+	return catalog, nil
+}
+
+// quoteEstimate prices req against the current catalog and plans its route.
+// It is shared by the estimate endpoint and the re-check at order time, where
+// requote is set: a stored estimate stays orderable while it is valid, even
+// once its scheduled start has passed.
+func quoteEstimate(ctx context.Context, q *db.Queries, cfg *config.EstimateConfig, req dto.EstimateRequest, requote bool) (estimateQuote, error) {
+	startCount := 0
+	startIdx := 0
+	for i, o := range req.Orders {
+		if o.IsStartingPoint {
+			startCount++
+			startIdx = i
+		}
+	}
+	if startCount != 1 {
+		return estimateQuote{}, errStartingPoint
+	}
+
+	if err := validateEstimateRequest(req, cfg, requote); err != nil {
+		return estimateQuote{}, err
+	}
+
+	now := time.Now()
+	start := now
+	if req.ScheduledAt != nil {
+		start = *req.ScheduledAt
+	}
+
+	settings, err := q.GetMerchantCategorySettings(ctx)
+	if err != nil {
+		return estimateQuote{}, errEstimateSettings
+	}
+	categorySettings := make(map[db.MerchantCategory]db.MerchantCategorySetting, len(settings))
+	for _, s := range settings {
+		categorySettings[s.MerchantCategory] = s
+	}
+
+	catalog, err := loadEstimateCatalog(ctx, q, req)
+	if err != nil {
+		return estimateQuote{}, err
+	}
+
+	totalPrice := 0.0
+	locations := make([]shared.RoutePoint, len(req.Orders))
+	subtotals := make([]int, len(req.Orders))
+	categories := make([]db.MerchantCategory, len(req.Orders))
+	openingHours := make([]shared.OpeningHours, len(req.Orders))
+	validationErr := &EstimateValidationError{}
+
+	// The same item may be listed more than once, stock must cover the sum
+	requested := make(map[pgtype.UUID]int)
+	for _, order := range req.Orders {
+		for _, item := range order.Items {
+			requested[parseUUID(item.ItemId)] += item.Quantity
+		}
+	}
+
+	for i, order := range req.Orders {
+		merchant, ok := catalog.merchants[parseUUID(order.MerchantId)]
+		if !ok {
+			validationErr.notFound(fmt.Sprintf("orders[%d].merchantId", i), "Merchant not found")
+			continue
+		}
+
+		if !merchant.Deliverable {
+			validationErr.invalid(
+				fmt.Sprintf("orders[%d].merchantId", i),
+				fmt.Sprintf("Merchant %s does not deliver to this location", order.MerchantId),
+			)
+			continue
+		}
+
+		// Without a schedule the order is prepared right away, so the merchant
+		// must be open now
+		hours := catalog.hours[merchant.ID]
+		if req.ScheduledAt == nil && !hours.IsOpen(now) {
+			validationErr.invalid(fmt.Sprintf("orders[%d].merchantId", i), closedMessage(order.MerchantId, hours, now))
+			continue
+		}
+		if _, ok := hours.NextOpen(start); !ok {
+			validationErr.invalid(
+				fmt.Sprintf("orders[%d].merchantId", i),
+				fmt.Sprintf("Merchant %s is not open within %d days of the requested time", order.MerchantId, shared.OpeningSearchDays),
+			)
+			continue
+		}
+
+		locations[i] = shared.RoutePoint{Lat: merchant.Lat, Long: merchant.Long}
+		categories[i] = merchant.MerchantCategory
+		openingHours[i] = hours
+
+		for j, item := range order.Items {
+			field := fmt.Sprintf("orders[%d].items[%d].itemId", i, j)
+
+			itemData, ok := catalog.items[parseUUID(item.ItemId)]
+			if !ok {
+				validationErr.notFound(field, "Item not found")
+				continue
+			}
+			if itemData.MerchantID != merchant.ID {
+				validationErr.invalid(field, "Item does not belong to merchant "+order.MerchantId)
+				continue
+			}
+			if !itemAvailable(itemData.Available, itemData.Stock) {
+				validationErr.invalid(field, fmt.Sprintf("Item %s is unavailable", item.ItemId))
+				continue
+			}
+			if itemData.Stock.Valid && int(itemData.Stock.Int32) < requested[itemData.ID] {
+				validationErr.invalid(field, fmt.Sprintf("Only %d of item %s left in stock", itemData.Stock.Int32, item.ItemId))
+				continue
+			}
+
+			delta, problem := itemOptionsPrice(catalog, itemData.ID, item.Options)
+			if problem != nil {
+				optionsField := fmt.Sprintf("orders[%d].items[%d].options", i, j)
+				if problem.notFound {
+					validationErr.notFound(optionsField, problem.message)
+				} else {
+					validationErr.invalid(optionsField, problem.message)
+				}
+				continue
+			}
+
+			linePrice := (itemData.Price + delta) * int32(item.Quantity)
+			subtotals[i] += int(linePrice)
+			totalPrice += float64(linePrice)
+		}
+	}
+
+	if len(validationErr.Errors) > 0 {
+		return estimateQuote{}, validationErr
+	}
+
+	visits, route, plan := planEstimateRoute(req, locations, startIdx)
+
+	stopPrepTimes := make([]float64, len(req.Orders))
+	charges := make([]shared.MerchantCharge, len(req.Orders))
+	for i, category := range categories {
+		setting := categorySettings[category]
+		stopPrepTimes[i] = float64(setting.PrepTimeMinutes)
+		charges[i] = shared.MerchantCharge{
+			Subtotal: subtotals[i],
+			Schedule: feeSchedule(setting),
+		}
+	}
+	timeline, delayed := buildEstimateTimeline(req, visits, plan, subtotals, stopPrepTimes, start, openingHours)
+	if req.ScheduledAt == nil && len(delayed) > 0 {
+		for _, idx := range delayed {
+			validationErr.invalid(
+				fmt.Sprintf("orders[%d].merchantId", idx),
+				fmt.Sprintf("Merchant %s closes before the order can be picked up", req.Orders[idx].MerchantId),
+			)
+		}
+		return estimateQuote{}, validationErr
+	}
+	if req.ScheduledAt != nil {
+		timeline.StartsAt = start.Format(shared.ISO8601WithNanoseconds)
+	}
+
+	subtotal := int(totalPrice)
+	fees := make([]dto.EstimateFee, 0, 4)
+	for _, line := range shared.CalculateFees(plan.Total, charges) {
+		fees = append(fees, dto.EstimateFee{Type: line.Type, Amount: line.Amount})
+		totalPrice += float64(line.Amount)
+	}
+
+	return estimateQuote{
+		Subtotal:     subtotal,
+		Fees:         fees,
+		TotalPrice:   math.Round(totalPrice*100) / 100,
+		DeliveryTime: math.Round(timeline.DropOffInMinutes*100) / 100,
+		Route:        route,
+		Timeline:     timeline,
+	}, nil
+}
+
+// validateEstimateRequest checks what can be checked without the catalog:
+// every merchant appears once, quantities stay under the configured cap and a
+// new scheduled delivery lies in the future.
+func validateEstimateRequest(req dto.EstimateRequest, cfg *config.EstimateConfig, requote bool) error {
+	validationErr := &EstimateValidationError{}
+
+	if !requote && req.ScheduledAt != nil && !req.ScheduledAt.After(time.Now()) {
+		validationErr.invalid("scheduledAt", "Scheduled time must be in the future")
+	}
+
+	seen := make(map[string]int, len(req.Orders))
+	for i, order := range req.Orders {
+		merchantID := strings.ToLower(order.MerchantId)
+		if first, ok := seen[merchantID]; ok {
+			validationErr.invalid(
+				fmt.Sprintf("orders[%d].merchantId", i),
+				fmt.Sprintf("Merchant is already listed in orders[%d]", first),
+			)
+		} else {
+			seen[merchantID] = i
+		}
+
+		for j, item := range order.Items {
+			if item.Quantity > cfg.MaxItemQuantity {
+				validationErr.invalid(
+					fmt.Sprintf("orders[%d].items[%d].quantity", i, j),
+					fmt.Sprintf("Quantity must not exceed %d", cfg.MaxItemQuantity),
+				)
+			}
+		}
+	}
+
+	if len(validationErr.Errors) > 0 {
+		return validationErr
+	}
+	return nil
+}
+
+// writeEstimateError maps a quoteEstimate error to its HTTP response.
+func writeEstimateError(c *gin.Context, err error) {
+	var validationErr *EstimateValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(validationErr.Code(), dto.ValidationErrorResponse{
+			ErrorResponse: dto.ErrorResponse{
+				Success: false,
+				Error:   "One or more orders are invalid",
+				Code:    validationErr.Code(),
+			},
+			Errors: validationErr.Errors,
+		})
+	case errors.Is(err, errStartingPoint):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "There must be exactly one starting point",
+			Code:    http.StatusBadRequest,
+		})
+	case errors.Is(err, errEstimateSettings):
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Success: false,
-			Error:   "Failed to store estimate",
+			Error:   "Failed to load estimate settings",
 			Code:    http.StatusInternalServerError,
 		})
-		return
+	case errors.Is(err, errEstimateCatalog):
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load merchants and items",
+			Code:    http.StatusInternalServerError,
+		})
+	default:
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "One of the merchants or items not found",
+			Code:    http.StatusNotFound,
+		})
 	}
+}
 
-	c.JSON(http.StatusOK, dto.EstimateResponse{
-		TotalPrice:                  roundedTotalPrice,
-		EstimatedDeliveryTimeInMins: roundedDeliveryTime,
-		CalculatedEstimateID:        estimateID.String(),
-	})
+// EstimateValidationError collects field level problems with an estimate request.
+type EstimateValidationError struct {
+	Errors []dto.FieldError
+	// missing counts the errors caused by a merchant or item that does not exist
+	missing int
+}
+
+func (e *EstimateValidationError) Error() string { return "invalid estimate request" }
+
+func (e *EstimateValidationError) notFound(field, message string) {
+	e.missing++
+	e.invalid(field, message)
+}
+
+func (e *EstimateValidationError) invalid(field, message string) {
+	e.Errors = append(e.Errors, dto.FieldError{Field: field, Message: message})
+}
+
+// Code is 404 when every problem is a missing merchant or item, 400 otherwise.
+func (e *EstimateValidationError) Code() int {
+	if e.missing == len(e.Errors) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// planEstimateRoute plans the courier path that starts at the merchant flagged
+// as the starting point, visits every other merchant and ends at the user.
+// visits lists the req.Orders indexes in visiting order.
+func planEstimateRoute(req dto.EstimateRequest, locations []shared.RoutePoint, startIdx int) (visits []int, route dto.EstimateRoute, plan shared.Route) {
+	// stopIdx maps a stop index back to its position in req.Orders
+	stops := make([]shared.RoutePoint, 0, len(req.Orders)-1)
+	stopIdx := make([]int, 0, len(req.Orders)-1)
+	for i := range req.Orders {
+		if i == startIdx {
+			continue
+		}
+		stops = append(stops, locations[i])
+		stopIdx = append(stopIdx, i)
+	}
+
+	end := shared.RoutePoint{Lat: req.UserLocation.Lat, Long: req.UserLocation.Long}
+	plan = shared.PlanRoute(locations[startIdx], stops, end)
+
+	visits = append(visits, startIdx)
+	for _, idx := range plan.Order {
+		visits = append(visits, stopIdx[idx])
+	}
+
+	visitOrder := make([]string, 0, len(visits))
+	for _, idx := range visits {
+		visitOrder = append(visitOrder, req.Orders[idx].MerchantId)
+	}
+
+	legs := make([]dto.EstimateRouteLeg, 0, len(plan.Legs))
+	for i, dist := range plan.Legs {
+		to := "userLocation"
+		if i+1 < len(visitOrder) {
+			to = visitOrder[i+1]
+		}
+		legs = append(legs, dto.EstimateRouteLeg{
+			From:         visitOrder[i],
+			To:           to,
+			DistanceInKm: math.Round(dist*1000) / 1000,
+		})
+	}
+
+	return visits, dto.EstimateRoute{
+		VisitOrder:        visitOrder,
+		Legs:              legs,
+		TotalDistanceInKm: math.Round(plan.Total*1000) / 1000,
+	}, plan
 }
 
-type DistanceError struct{}
+func feeSchedule(s db.MerchantCategorySetting) shared.FeeSchedule {
+	return shared.FeeSchedule{
+		BaseDeliveryFee:     int(s.BaseDeliveryFee),
+		PerKmFee:            int(s.PerKmFee),
+		SmallOrderThreshold: int(s.SmallOrderThreshold),
+		SmallOrderSurcharge: int(s.SmallOrderSurcharge),
+		ServiceFeePercent:   int(s.ServiceFeePercent),
+	}
+}
 
-func (e *DistanceError) Error() string { return "distance exceeds 3km" }
+// courierSpeedKmh is the average courier speed used to turn distance into time.
+const courierSpeedKmh = 40.0
+
+// buildEstimateTimeline walks the planned route from start. Every merchant
+// starts preparing once it is open, so the courier leaves a stop at the later
+// of its arrival and the end of the prep time. When the merchant is closed by
+// then the courier waits for it to reopen; the req.Orders indexes of those
+// stops are returned as delayed.
+func buildEstimateTimeline(req dto.EstimateRequest, visits []int, plan shared.Route, subtotals []int, prepTimes []float64, start time.Time, hours []shared.OpeningHours) (dto.EstimateTimeline, []int) {
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	at := func(minutes float64) time.Time { return start.Add(time.Duration(minutes * float64(time.Minute))) }
+	since := func(t time.Time) float64 { return t.Sub(start).Minutes() }
+
+	var delayed []int
+	stops := make([]dto.EstimateTimelineStop, 0, len(visits))
+	clock := 0.0
+	for i, idx := range visits {
+		if i > 0 {
+			clock += plan.Legs[i-1] / courierSpeedKmh * 60
+		}
+		arrival := clock
+
+		ready := prepTimes[idx]
+		if opens, ok := hours[idx].NextOpen(start); ok {
+			ready += since(opens)
+		}
+		clock = math.Max(clock, ready)
+
+		pickup, ok := hours[idx].NextOpen(at(clock))
+		if !ok || pickup.After(at(clock)) {
+			delayed = append(delayed, idx)
+		}
+		if ok {
+			clock = since(pickup)
+		}
+
+		stops = append(stops, dto.EstimateTimelineStop{
+			MerchantID:         req.Orders[idx].MerchantId,
+			Subtotal:           subtotals[idx],
+			ArrivalInMinutes:   round(arrival),
+			PrepTimeInMinutes:  prepTimes[idx],
+			DepartureInMinutes: round(clock),
+		})
+	}
+	clock += plan.Legs[len(plan.Legs)-1] / courierSpeedKmh * 60
+
+	return dto.EstimateTimeline{
+		Stops:            stops,
+		DropOffInMinutes: round(clock),
+	}, delayed
+}
+
+// closedMessage explains that a merchant is closed at t and when it reopens.
+func closedMessage(merchantID string, hours shared.OpeningHours, t time.Time) string {
+	if opensAt := nextOpening(hours, t); opensAt != nil {
+		return fmt.Sprintf("Merchant %s is closed until %s", merchantID, *opensAt)
+	}
+	return fmt.Sprintf("Merchant %s is closed", merchantID)
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// loadItemOptionGroups fetches the option groups of items with one statement
+// for the groups and one for their options, keyed by item ID.
+func loadItemOptionGroups(ctx context.Context, q *db.Queries, itemIDs []pgtype.UUID) (map[pgtype.UUID][]dto.ItemOptionGroup, error) {
+	groups, err := q.GetItemOptionGroups(ctx, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	options, err := q.GetItemOptions(ctx, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	groupOptions := make(map[pgtype.UUID][]dto.ItemOption, len(groups))
+	for _, o := range options {
+		groupOptions[o.GroupID] = append(groupOptions[o.GroupID], dto.ItemOption{
+			OptionID:   o.ID.String(),
+			Name:       o.Name,
+			PriceDelta: int(o.PriceDelta),
+		})
+	}
+
+	itemGroups := make(map[pgtype.UUID][]dto.ItemOptionGroup, len(itemIDs))
+	for _, g := range groups {
+		itemGroups[g.ItemID] = append(itemGroups[g.ItemID], dto.ItemOptionGroup{
+			GroupID:       g.ID.String(),
+			Name:          g.Name,
+			Required:      g.MinSelections > 0,
+			MinSelections: int(g.MinSelections),
+			MaxSelections: int(g.MaxSelections),
+			Options:       groupOptions[g.ID],
+		})
+	}
+	return itemGroups, nil
+}
+
+// optionProblem explains why the options chosen for an item cannot be ordered
+type optionProblem struct {
+	notFound bool
+	message  string
+}
+
+// itemOptionsPrice checks the options chosen for an item against its groups
+// and returns what they add to the unit price.
+func itemOptionsPrice(catalog estimateCatalog, itemID pgtype.UUID, chosen []string) (int32, *optionProblem) {
+	delta := int32(0)
+	counts := make(map[pgtype.UUID]int)
+	seen := make(map[pgtype.UUID]bool, len(chosen))
+	for _, id := range chosen {
+		option, ok := catalog.options[parseUUID(id)]
+		if !ok || option.ItemID != itemID {
+			return 0, &optionProblem{notFound: true, message: fmt.Sprintf("Option %s not found for this item", id)}
+		}
+		if seen[option.ID] {
+			return 0, &optionProblem{message: fmt.Sprintf("Option %s is chosen more than once", id)}
+		}
+		seen[option.ID] = true
+		counts[option.GroupID]++
+		delta += option.PriceDelta
+	}
+
+	for _, group := range catalog.optionGroups[itemID] {
+		count := counts[group.ID]
+		if count < int(group.MinSelections) {
+			return 0, &optionProblem{message: fmt.Sprintf("Choose at least %d from %s", group.MinSelections, group.Name)}
+		}
+		if count > int(group.MaxSelections) {
+			return 0, &optionProblem{message: fmt.Sprintf("Choose at most %d from %s", group.MaxSelections, group.Name)}
+		}
+	}
+	return delta, nil
+}
+
+// ReplaceItemOptionGroups sets the option groups of an item. Estimates made
+// with the previous options can no longer be ordered, past orders keep the
+// options they were placed with.
+func (h *MerchantHandler) ReplaceItemOptionGroups(c *gin.Context) {
+	var payload dto.ItemOptionGroupsRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: every group needs a name, at least one option and a maxSelections of at least 1 and not below minSelections, option prices must not be negative",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	merchantUUID, itemUUID, ok := parseMerchantItemIDs(c)
+	if !ok {
+		return
+	}
+
+	for i, group := range payload.OptionGroups {
+		if group.MinSelections > len(group.Options) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("optionGroups[%d] requires more options than it has", i),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	ctx := context.Background()
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	defer tx.Rollback(ctx)
+	qtx := db.New(tx)
+
+	_, err = qtx.GetMerchantItemForUpdate(ctx, db.GetMerchantItemForUpdateParams{
+		ID:         itemUUID,
+		MerchantID: merchantUUID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Item not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	if err := qtx.DeleteItemOptionGroups(ctx, itemUUID); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	for i, group := range payload.OptionGroups {
+		groupID, err := qtx.CreateItemOptionGroup(ctx, db.CreateItemOptionGroupParams{
+			ItemID:        itemUUID,
+			Name:          strings.TrimSpace(group.Name),
+			MinSelections: int32(group.MinSelections),
+			MaxSelections: int32(group.MaxSelections),
+			Position:      int32(i),
+		})
+		if err != nil {
+			writeOrderStatusError(c, err)
+			return
+		}
+
+		names := make([]string, len(group.Options))
+		priceDeltas := make([]int32, len(group.Options))
+		positions := make([]int32, len(group.Options))
+		for j, option := range group.Options {
+			names[j] = strings.TrimSpace(option.Name)
+			priceDeltas[j] = int32(option.PriceDelta)
+			positions[j] = int32(j)
+		}
+		err = qtx.CreateItemOptions(ctx, db.CreateItemOptionsParams{
+			GroupID:     groupID,
+			Names:       names,
+			PriceDeltas: priceDeltas,
+			Positions:   positions,
+		})
+		if err != nil {
+			writeOrderStatusError(c, err)
+			return
+		}
+	}
+
+	groups, err := loadItemOptionGroups(ctx, qtx, []pgtype.UUID{itemUUID})
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	optionGroups := groups[itemUUID]
+	if optionGroups == nil {
+		optionGroups = []dto.ItemOptionGroup{}
+	}
+	c.JSON(http.StatusOK, dto.ItemOptionGroupsResponse{
+		ItemID:       itemUUID.String(),
+		OptionGroups: optionGroups,
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
@@ -23,6 +24,9 @@ func NewMerchantHandler(pool *pgxpool.Pool) *MerchantHandler {
 	return &MerchantHandler{pool: pool}
 }
 
+// defaultDeliveryRadiusM is used when a merchant is created without a radius
+const defaultDeliveryRadiusM = 3000
+
 // isValidImageURL validates if a URL is a proper image URL
 func isValidImageURL(imageURL string) bool {
 	if imageURL == "" {
@@ -104,6 +108,11 @@ func (h *MerchantHandler) CreateMerchant(c *gin.Context) {
 		return
 	}
 
+	deliveryRadius := defaultDeliveryRadiusM
+	if payload.DeliveryRadiusM != nil {
+		deliveryRadius = *payload.DeliveryRadiusM
+	}
+
 	queries := db.New(h.pool)
 	ctx := context.Background()
 
@@ -113,6 +122,7 @@ func (h *MerchantHandler) CreateMerchant(c *gin.Context) {
 		ImageUrl:         payload.ImageURL,
 		StMakepoint:      payload.Location.Long,
 		StMakepoint_2:    payload.Location.Lat,
+		DeliveryRadiusM:  int32(deliveryRadius),
 	})
 	if err != nil {
 		statusCode, errorMessage := shared.ParseDBResult(err)
@@ -135,6 +145,8 @@ func (h *MerchantHandler) GetMerchants(c *gin.Context) {
 	name := c.Query("name")
 	merchantCategory := c.Query("merchantCategory")
 	createdAt := c.Query("createdAt")
+	// Admins find deleted rows to restore with deleted=true
+	deleted := c.Query("deleted") == "true"
 
 	// Parse limit and offset with defaults
 	limit := int32(5)
@@ -169,7 +181,8 @@ func (h *MerchantHandler) GetMerchants(c *gin.Context) {
 		"ConvenienceStore":      true,
 	}
 
-	if merchantCategory != "" && !validCategories[merchantCategory] {
+	minRating, ratingSort, validRating := parseRatingFilters(c)
+	if (merchantCategory != "" && !validCategories[merchantCategory]) || !validRating {
 		c.JSON(http.StatusOK, dto.GetMerchantsResponse{
 			Data: []dto.MerchantData{},
 			Meta: dto.MerchantMeta{
@@ -216,6 +229,8 @@ func (h *MerchantHandler) GetMerchants(c *gin.Context) {
 		MerchantID:       merchantIDText,
 		MerchantCategory: categoryText,
 		Name:             nameText,
+		MinRating:        minRating,
+		Deleted:          deleted,
 	})
 	if err != nil {
 		statusCode, errorMessage := shared.ParseDBResult(err)
@@ -232,6 +247,9 @@ func (h *MerchantHandler) GetMerchants(c *gin.Context) {
 		MerchantID:       merchantIDText,
 		MerchantCategory: categoryText,
 		Name:             nameText,
+		MinRating:        minRating,
+		Deleted:          deleted,
+		Rating:           ratingSort,
 		CreatedAt:        createdAt,
 		OffsetVal:        offset,
 		LimitVal:         limit,
@@ -277,7 +295,10 @@ func (h *MerchantHandler) GetMerchants(c *gin.Context) {
 				Lat:  lat,
 				Long: long,
 			},
-			CreatedAt: m.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+			DeliveryRadiusM: int(m.DeliveryRadiusM),
+			Rating:          merchantRating(m.RatingCount, m.RatingAverage),
+			CreatedAt:       m.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+			DeletedAt:       formatOptionalTime(m.DeletedAt),
 		})
 	}
 
@@ -318,8 +339,8 @@ func (h *MerchantHandler) CreateMerchantItem(c *gin.Context) {
 		return
 	}
 
-	// Check if merchant exists
-	exists, err := queries.GetMerchantByID(ctx, merchantUUID)
+	// Check if merchant exists, deleted merchants get no new items
+	exists, err := queries.GetActiveMerchantByID(ctx, merchantUUID)
 	if err != nil {
 		statusCode, errorMessage := shared.ParseDBResult(err)
 		c.JSON(statusCode, dto.ErrorResponse{
@@ -416,6 +437,8 @@ func (h *MerchantHandler) GetMerchantItems(c *gin.Context) {
 	name := c.Query("name")
 	productCategory := c.Query("productCategory")
 	createdAt := c.Query("createdAt")
+	// Admins find deleted rows to restore with deleted=true
+	deleted := c.Query("deleted") == "true"
 
 	// Parse limit and offset with defaults
 	limit := int32(5)
@@ -529,6 +552,7 @@ func (h *MerchantHandler) GetMerchantItems(c *gin.Context) {
 		ItemID:          itemIDText,
 		ProductCategory: productCategoryText,
 		Name:            nameText,
+		Deleted:         deleted,
 	})
 	if err != nil {
 		statusCode, errorMessage := shared.ParseDBResult(err)
@@ -546,6 +570,7 @@ func (h *MerchantHandler) GetMerchantItems(c *gin.Context) {
 		ItemID:          itemIDText,
 		ProductCategory: productCategoryText,
 		Name:            nameText,
+		Deleted:         deleted,
 		CreatedAt:       createdAt,
 		OffsetVal:       offset,
 		LimitVal:        limit,
@@ -560,6 +585,21 @@ func (h *MerchantHandler) GetMerchantItems(c *gin.Context) {
 		return
 	}
 
+	itemIDs := make([]pgtype.UUID, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ID)
+	}
+	optionGroups, err := loadItemOptionGroups(ctx, queries, itemIDs)
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
 	// Convert to response format
 	itemData := make([]dto.MerchantItemData, 0, len(items))
 	for _, item := range items {
@@ -569,13 +609,23 @@ func (h *MerchantHandler) GetMerchantItems(c *gin.Context) {
 			uuidStr = pgtype.UUID{Bytes: item.ID.Bytes, Valid: true}.String()
 		}
 
+		var stock *int
+		if item.Stock.Valid {
+			count := int(item.Stock.Int32)
+			stock = &count
+		}
+
 		itemData = append(itemData, dto.MerchantItemData{
 			ItemId:          uuidStr,
 			Name:            item.Name,
 			ProductCategory: string(item.ProductCategory),
 			Price:           int(item.Price),
 			ImageURL:        item.ImageUrl,
+			Available:       itemAvailable(item.Available, item.Stock),
+			Stock:           stock,
 			CreatedAt:       item.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+			DeletedAt:       formatOptionalTime(item.DeletedAt),
+			OptionGroups:    optionGroups[item.ID],
 		})
 	}
 
@@ -672,7 +722,8 @@ func (h *MerchantHandler) GetNearbyMerchants(c *gin.Context) {
 		"BoothKiosk":            true,
 		"ConvenienceStore":      true,
 	}
-	if merchantCategory != "" && !validCategories[merchantCategory] {
+	minRating, ratingSort, validRating := parseRatingFilters(c)
+	if (merchantCategory != "" && !validCategories[merchantCategory]) || !validRating {
 		c.JSON(http.StatusOK, dto.GetNearbyMerchantsResponse{
 			Data: []dto.NearbyMerchant{},
 			Meta: dto.MerchantMeta{
@@ -715,9 +766,12 @@ func (h *MerchantHandler) GetNearbyMerchants(c *gin.Context) {
 	}
 
 	total, err := queries.CountNearbyMerchants(ctx, db.CountNearbyMerchantsParams{
+		Lat:              lat,
+		Long:             long,
 		MerchantID:       merchantIDText,
 		MerchantCategory: categoryText,
 		Name:             nameText,
+		MinRating:        minRating,
 	})
 	if err != nil {
 		statusCode, errorMessage := shared.ParseDBResult(err)
@@ -735,6 +789,8 @@ func (h *MerchantHandler) GetNearbyMerchants(c *gin.Context) {
 		MerchantID:       merchantIDText,
 		MerchantCategory: categoryText,
 		Name:             nameText,
+		MinRating:        minRating,
+		Rating:           ratingSort,
 		RowLimit:         limit,
 		RowOffset:        offset,
 	})
@@ -748,8 +804,29 @@ func (h *MerchantHandler) GetNearbyMerchants(c *gin.Context) {
 		return
 	}
 
+	now := time.Now()
+	timeZones := make(map[pgtype.UUID]string, len(rows))
+	for _, m := range rows {
+		timeZones[m.ID] = m.TimeZone
+	}
+	openingHours, err := loadOpeningHours(ctx, queries, timeZones, now)
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
 	// Assemble response with items per merchant
-	resp := make([]dto.NearbyMerchant, 0, len(rows))
+	type nearbyEntry struct {
+		merchant dto.MerchantData
+		items    []db.GetMerchantItemsRow
+	}
+	entries := make([]nearbyEntry, 0, len(rows))
+	var itemIDs []pgtype.UUID
 	for _, m := range rows {
 		// Convert UUID to string
 		merchantIDStr := ""
@@ -769,9 +846,17 @@ func (h *MerchantHandler) GetNearbyMerchants(c *gin.Context) {
 				Lat:  lat64,
 				Long: long64,
 			},
+			Rating:    merchantRating(m.RatingCount, m.RatingAverage),
 			CreatedAt: m.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
 		}
 
+		hours := openingHours[m.ID]
+		isOpen := hours.IsOpen(now)
+		merchantData.IsOpen = &isOpen
+		if !isOpen {
+			merchantData.OpensAt = nextOpening(hours, now)
+		}
+
 		// Fetch items for this merchant (no extra filters), unpaged
 		var merchantUUIDForItems pgtype.UUID
 		if err := merchantUUIDForItems.Scan(merchantIDStr); err != nil {
@@ -797,8 +882,28 @@ func (h *MerchantHandler) GetNearbyMerchants(c *gin.Context) {
 			return
 		}
 
-		itemData := make([]dto.MerchantItemData, 0, len(items))
 		for _, it := range items {
+			itemIDs = append(itemIDs, it.ID)
+		}
+		entries = append(entries, nearbyEntry{merchant: merchantData, items: items})
+	}
+
+	// The option groups of every item on the page in one go
+	optionGroups, err := loadItemOptionGroups(ctx, queries, itemIDs)
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	resp := make([]dto.NearbyMerchant, 0, len(entries))
+	for _, entry := range entries {
+		itemData := make([]dto.MerchantItemData, 0, len(entry.items))
+		for _, it := range entry.items {
 			itemIDStr := ""
 			if it.ID.Valid {
 				itemIDStr = pgtype.UUID{Bytes: it.ID.Bytes, Valid: true}.String()
@@ -809,12 +914,14 @@ func (h *MerchantHandler) GetNearbyMerchants(c *gin.Context) {
 				ProductCategory: string(it.ProductCategory),
 				Price:           int(it.Price),
 				ImageURL:        it.ImageUrl,
+				Available:       itemAvailable(it.Available, it.Stock),
 				CreatedAt:       it.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+				OptionGroups:    optionGroups[it.ID],
 			})
 		}
 
 		resp = append(resp, dto.NearbyMerchant{
-			Merchant: merchantData,
+			Merchant: entry.merchant,
 			Items:    itemData,
 		})
 	}
@@ -0,0 +1,410 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// orderMerchantTransitions lists the statuses a merchant's part of an order
+// may move to from each status.
+var orderMerchantTransitions = map[db.OrderMerchantStatus][]db.OrderMerchantStatus{
+	db.OrderMerchantStatusPending:  {db.OrderMerchantStatusAccepted, db.OrderMerchantStatusRejected},
+	db.OrderMerchantStatusAccepted: {db.OrderMerchantStatusReady},
+}
+
+func canTransitionOrderMerchant(from, to db.OrderMerchantStatus) bool {
+	for _, next := range orderMerchantTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderMerchantTransitionError is returned when a merchant's part of an order
+// cannot move to the requested status.
+type OrderMerchantTransitionError struct {
+	OrderStatus db.OrderStatus
+	From        db.OrderMerchantStatus
+	To          db.OrderMerchantStatus
+}
+
+func (e *OrderMerchantTransitionError) Error() string {
+	return fmt.Sprintf("merchant part of a %s order cannot move from %s to %s", e.OrderStatus, e.From, e.To)
+}
+
+// transitionOrderMerchant moves one merchant's part of an order and rolls the
+// parts up into the order status: the order is accepted once every merchant
+// accepted, rejected as soon as one merchant rejects and preparing once the
+// first merchant is ready. It returns the resulting order status.
+func transitionOrderMerchant(ctx context.Context, q *db.Queries, orderID, merchantID pgtype.UUID, to db.OrderMerchantStatus, actor orderActor) (db.OrderStatus, error) {
+	// Lock the order before its parts, like transitionOrder does
+	orderStatus, err := q.GetOrderStatusForUpdate(ctx, orderID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", errOrderNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	from, err := q.GetOrderMerchantStatus(ctx, db.GetOrderMerchantStatusParams{
+		OrderID:    orderID,
+		MerchantID: merchantID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", errOrderNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	orderAllows := orderStatus == db.OrderStatusPlaced
+	if to == db.OrderMerchantStatusReady {
+		orderAllows = orderStatus == db.OrderStatusAccepted || orderStatus == db.OrderStatusPreparing
+	}
+	if !orderAllows || !canTransitionOrderMerchant(from, to) {
+		return "", &OrderMerchantTransitionError{OrderStatus: orderStatus, From: from, To: to}
+	}
+
+	err = q.UpdateOrderMerchantStatus(ctx, db.UpdateOrderMerchantStatusParams{
+		OrderID:    orderID,
+		MerchantID: merchantID,
+		Status:     to,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	next := orderStatus
+	switch to {
+	case db.OrderMerchantStatusRejected:
+		next = db.OrderStatusRejected
+	case db.OrderMerchantStatusAccepted:
+		statuses, err := q.GetOrderMerchantStatuses(ctx, orderID)
+		if err != nil {
+			return "", err
+		}
+		allAccepted := true
+		for _, s := range statuses {
+			allAccepted = allAccepted && s == db.OrderMerchantStatusAccepted
+		}
+		if allAccepted {
+			next = db.OrderStatusAccepted
+		}
+	case db.OrderMerchantStatusReady:
+		if orderStatus == db.OrderStatusAccepted {
+			next = db.OrderStatusPreparing
+		}
+	}
+
+	if next != orderStatus {
+		if err := transitionOrder(ctx, q, orderID, next, actor); err != nil {
+			return "", err
+		}
+	}
+	return next, nil
+}
+
+func (h *OrderHandler) AcceptMerchantOrder(c *gin.Context) {
+	h.updateMerchantOrder(c, db.OrderMerchantStatusAccepted)
+}
+
+func (h *OrderHandler) RejectMerchantOrder(c *gin.Context) {
+	h.updateMerchantOrder(c, db.OrderMerchantStatusRejected)
+}
+
+func (h *OrderHandler) MarkMerchantOrderReady(c *gin.Context) {
+	h.updateMerchantOrder(c, db.OrderMerchantStatusReady)
+}
+
+func (h *OrderHandler) updateMerchantOrder(c *gin.Context, to db.OrderMerchantStatus) {
+	var merchantID, orderID pgtype.UUID
+	if err := merchantID.Scan(c.Param("merchantId")); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid merchant ID format",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if err := orderID.Scan(c.Param("orderId")); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Order not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	username, _ := c.Get("username")
+	admin, err := h.Q.GetAdminByUsername(c, username.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "Admin not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	tx, err := h.pool.Begin(c)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	orderStatus, err := transitionOrderMerchant(c, h.Q.WithTx(tx), orderID, merchantID, to, userActor(admin))
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if err := tx.Commit(c); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if orderStatus == db.OrderStatusRejected {
+		h.settleRefund(c, orderID)
+	}
+
+	c.JSON(http.StatusOK, dto.MerchantOrderStatusResponse{
+		OrderID:     orderID.String(),
+		MerchantID:  merchantID.String(),
+		Status:      string(to),
+		OrderStatus: string(orderStatus),
+	})
+}
+
+// parseDateFilter reads an RFC 3339 timestamp or a YYYY-MM-DD date. A bare
+// date used as an upper bound covers the whole day.
+func parseDateFilter(value string, upper bool) (pgtype.Timestamptz, error) {
+	if value == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return pgtype.Timestamptz{Time: t, Valid: true}, nil
+	}
+	t, err := time.Parse(shared.DateLayout, value)
+	if err != nil {
+		return pgtype.Timestamptz{}, err
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
+
+func (h *OrderHandler) GetMerchantOrders(c *gin.Context) {
+	status := c.Query("status")
+
+	// Parse limit and offset with defaults
+	limit := int32(5)
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if val, err := strconv.ParseInt(limitStr, 10, 32); err == nil && val > 0 {
+			limit = int32(val)
+		}
+	}
+
+	offset := int32(0)
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if val, err := strconv.ParseInt(offsetStr, 10, 32); err == nil && val >= 0 {
+			offset = int32(val)
+		}
+	}
+
+	var merchantID pgtype.UUID
+	if err := merchantID.Scan(c.Param("merchantId")); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid merchant ID format",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	createdFrom, errFrom := parseDateFilter(c.Query("from"), false)
+	createdTo, errTo := parseDateFilter(c.Query("to"), true)
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid date filter. Use RFC 3339 timestamps or YYYY-MM-DD dates",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	exists, err := h.Q.GetMerchantByID(c, merchantID)
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Merchant not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	// status matches either the merchant's sub-status or the order status
+	validStatuses := map[string]bool{
+		"pending":   true,
+		"accepted":  true,
+		"ready":     true,
+		"rejected":  true,
+		"placed":    true,
+		"preparing": true,
+		"picked_up": true,
+		"delivered": true,
+		"cancelled": true,
+	}
+	if status != "" && !validStatuses[status] {
+		c.JSON(http.StatusOK, dto.GetMerchantOrdersResponse{
+			Data: []dto.MerchantOrderData{},
+			Meta: dto.MerchantMeta{
+				Limit:  int(limit),
+				Offset: int(offset),
+				Total:  0,
+			},
+		})
+		return
+	}
+	var statusText pgtype.Text
+	if status != "" {
+		statusText = pgtype.Text{String: status, Valid: true}
+	}
+
+	total, err := h.Q.CountMerchantOrders(c, db.CountMerchantOrdersParams{
+		MerchantID:  merchantID,
+		Status:      statusText,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	rows, err := h.Q.GetMerchantOrders(c, db.GetMerchantOrdersParams{
+		MerchantID:  merchantID,
+		Status:      statusText,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+		OffsetVal:   offset,
+		LimitVal:    limit,
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	// Only this merchant's items and the options chosen for them
+	orderIDs := make([]pgtype.UUID, 0, len(rows))
+	for _, r := range rows {
+		orderIDs = append(orderIDs, r.ID)
+	}
+	itemRows, err := h.Q.GetMerchantOrderItems(c, db.GetMerchantOrderItemsParams{
+		OrderIds:   orderIDs,
+		MerchantID: merchantID,
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+	optionRows, err := h.Q.GetMerchantOrderItemOptions(c, db.GetMerchantOrderItemOptionsParams{
+		OrderIds:   orderIDs,
+		MerchantID: merchantID,
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	type itemKey struct {
+		orderID  pgtype.UUID
+		position int32
+	}
+	options := make(map[itemKey][]dto.OrderItemOption)
+	for _, o := range optionRows {
+		key := itemKey{o.OrderID, o.ItemPosition}
+		options[key] = append(options[key], dto.OrderItemOption{
+			OptionID:   o.OptionID.String(),
+			GroupName:  o.GroupName,
+			Name:       o.Name,
+			PriceDelta: int(o.PriceDelta),
+		})
+	}
+	items := make(map[pgtype.UUID][]dto.MerchantOrderItem, len(rows))
+	for _, it := range itemRows {
+		items[it.OrderID] = append(items[it.OrderID], dto.MerchantOrderItem{
+			ItemID:   it.ItemID.String(),
+			Name:     it.Name,
+			Quantity: int(it.Quantity),
+			Options:  options[itemKey{it.OrderID, it.Position}],
+		})
+	}
+
+	data := make([]dto.MerchantOrderData, 0, len(rows))
+	for _, r := range rows {
+		orderItems := items[r.ID]
+		if orderItems == nil {
+			orderItems = []dto.MerchantOrderItem{}
+		}
+
+		data = append(data, dto.MerchantOrderData{
+			OrderID:     r.ID.String(),
+			OrderStatus: string(r.OrderStatus),
+			Status:      string(r.Status),
+			Position:    int(r.Position),
+			Subtotal:    int(r.Subtotal),
+			Items:       orderItems,
+			CreatedAt:   r.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.GetMerchantOrdersResponse{
+		Data: data,
+		Meta: dto.MerchantMeta{
+			Limit:  int(limit),
+			Offset: int(offset),
+			Total:  int(total),
+		},
+	})
+}
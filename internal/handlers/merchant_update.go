@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// UpdateMerchant changes the fields given of a merchant. Orders placed before
+// keep the details they were placed with.
+func (h *MerchantHandler) UpdateMerchant(c *gin.Context) {
+	var payload dto.MerchantUpdateRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: name must be 2 to 30 characters and deliveryRadiusM at least 1",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	merchantUUID, ok := parseMerchantID(c)
+	if !ok {
+		return
+	}
+
+	params := db.UpdateMerchantParams{ID: merchantUUID}
+	if payload.Name != nil {
+		name := strings.TrimSpace(*payload.Name)
+		if l := len(name); l < 2 || l > 30 {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   "Invalid name",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		params.Name = pgtype.Text{String: name, Valid: true}
+	}
+	if payload.MerchantCategory != nil {
+		if !dto.ValidMerchantCategories[*payload.MerchantCategory] {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   "Invalid merchant category. Must be one of: SmallRestaurant, MediumRestaurant, LargeRestaurant, MerchandiseRestaurant, BoothKiosk, ConvenienceStore",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		params.MerchantCategory = db.NullMerchantCategory{MerchantCategory: db.MerchantCategory(*payload.MerchantCategory), Valid: true}
+	}
+	if payload.ImageURL != nil {
+		if !isValidImageURL(*payload.ImageURL) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   "Invalid image URL. Must be a complete HTTP/HTTPS URL with a path (e.g., https://example.com/image.jpg)",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		params.ImageUrl = pgtype.Text{String: *payload.ImageURL, Valid: true}
+	}
+	if payload.Location != nil {
+		lat, long := payload.Location.Lat, payload.Location.Long
+		if lat < -90 || lat > 90 || lat == 0 || long < -180 || long > 180 || long == 0 {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   "Invalid location. Latitude must be between -90 and 90 and longitude between -180 and 180, neither zero",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		params.Lat = pgtype.Float8{Float64: lat, Valid: true}
+		params.Long = pgtype.Float8{Float64: long, Valid: true}
+	}
+	if payload.DeliveryRadiusM != nil {
+		params.DeliveryRadiusM = pgtype.Int4{Int32: int32(*payload.DeliveryRadiusM), Valid: true}
+	}
+
+	queries := db.New(h.pool)
+	ctx := context.Background()
+
+	m, err := queries.UpdateMerchant(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Merchant not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MerchantData{
+		MerchantID:       m.ID.String(),
+		Name:             m.Name,
+		MerchantCategory: string(m.MerchantCategory),
+		ImageURL:         m.ImageUrl,
+		Location:         dto.Location{Lat: m.Lat, Long: m.Long},
+		DeliveryRadiusM:  int(m.DeliveryRadiusM),
+		CreatedAt:        m.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+	})
+}
+
+// DeleteMerchant hides a merchant and its items from the catalog. The rows
+// are kept for the orders and reviews referencing them.
+func (h *MerchantHandler) DeleteMerchant(c *gin.Context) {
+	merchantUUID, ok := parseMerchantID(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := db.New(h.pool).DeleteMerchant(context.Background(), merchantUUID)
+	writeSoftDeleteResult(c, deleted, err, "Merchant not found")
+}
+
+// RestoreMerchant brings a deleted merchant back into the catalog
+func (h *MerchantHandler) RestoreMerchant(c *gin.Context) {
+	merchantUUID, ok := parseMerchantID(c)
+	if !ok {
+		return
+	}
+
+	restored, err := db.New(h.pool).RestoreMerchant(context.Background(), merchantUUID)
+	writeSoftDeleteResult(c, restored, err, "Deleted merchant not found")
+}
+
+// UpdateMerchantItem changes the fields given of an item. Orders placed
+// before keep the name and price they were placed with.
+func (h *MerchantHandler) UpdateMerchantItem(c *gin.Context) {
+	var payload dto.MerchantItemUpdateRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: name must be 2 to 30 characters and price at least 1",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	merchantUUID, itemUUID, ok := parseMerchantItemIDs(c)
+	if !ok {
+		return
+	}
+
+	params := db.UpdateMerchantItemParams{ID: itemUUID, MerchantID: merchantUUID}
+	if payload.Name != nil {
+		name := strings.TrimSpace(*payload.Name)
+		if l := len(name); l < 2 || l > 30 {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   "Invalid name",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		params.Name = pgtype.Text{String: name, Valid: true}
+	}
+	if payload.ProductCategory != nil {
+		switch *payload.ProductCategory {
+		case dto.Beverage, dto.Food, dto.Snack, dto.Condiments, dto.Additions:
+		default:
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   "Invalid product category",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		params.ProductCategory = db.NullProductCategory{ProductCategory: db.ProductCategory(*payload.ProductCategory), Valid: true}
+	}
+	if payload.Price != nil {
+		params.Price = pgtype.Int4{Int32: int32(*payload.Price), Valid: true}
+	}
+	if payload.ImageURL != nil {
+		if !isValidImageURL(*payload.ImageURL) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   "Invalid image URL",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		params.ImageUrl = pgtype.Text{String: *payload.ImageURL, Valid: true}
+	}
+
+	queries := db.New(h.pool)
+	ctx := context.Background()
+
+	item, err := queries.UpdateMerchantItem(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Item not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	var stock *int
+	if item.Stock.Valid {
+		count := int(item.Stock.Int32)
+		stock = &count
+	}
+	c.JSON(http.StatusOK, dto.MerchantItemData{
+		ItemId:          item.ID.String(),
+		Name:            item.Name,
+		ProductCategory: string(item.ProductCategory),
+		Price:           int(item.Price),
+		ImageURL:        item.ImageUrl,
+		Available:       itemAvailable(item.Available, item.Stock),
+		Stock:           stock,
+		CreatedAt:       item.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+	})
+}
+
+// DeleteMerchantItem hides an item from the catalog. Estimates holding it can
+// no longer be ordered.
+func (h *MerchantHandler) DeleteMerchantItem(c *gin.Context) {
+	merchantUUID, itemUUID, ok := parseMerchantItemIDs(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := db.New(h.pool).DeleteMerchantItem(context.Background(), db.DeleteMerchantItemParams{
+		ID:         itemUUID,
+		MerchantID: merchantUUID,
+	})
+	writeSoftDeleteResult(c, deleted, err, "Item not found")
+}
+
+// RestoreMerchantItem brings a deleted item back into the catalog
+func (h *MerchantHandler) RestoreMerchantItem(c *gin.Context) {
+	merchantUUID, itemUUID, ok := parseMerchantItemIDs(c)
+	if !ok {
+		return
+	}
+
+	restored, err := db.New(h.pool).RestoreMerchantItem(context.Background(), db.RestoreMerchantItemParams{
+		ID:         itemUUID,
+		MerchantID: merchantUUID,
+	})
+	writeSoftDeleteResult(c, restored, err, "Deleted item not found")
+}
+
+// parseMerchantID reads the merchantId path parameter, writing the error
+// response when it is malformed.
+func parseMerchantID(c *gin.Context) (pgtype.UUID, bool) {
+	var merchantUUID pgtype.UUID
+	if err := merchantUUID.Scan(c.Param("merchantId")); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid merchant ID format",
+			Code:    http.StatusBadRequest,
+		})
+		return merchantUUID, false
+	}
+	return merchantUUID, true
+}
+
+// parseMerchantItemIDs reads the merchantId and itemId path parameters,
+// writing the error response when one is malformed.
+func parseMerchantItemIDs(c *gin.Context) (pgtype.UUID, pgtype.UUID, bool) {
+	var itemUUID pgtype.UUID
+	merchantUUID, ok := parseMerchantID(c)
+	if !ok {
+		return merchantUUID, itemUUID, false
+	}
+	if err := itemUUID.Scan(c.Param("itemId")); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid item ID format",
+			Code:    http.StatusBadRequest,
+		})
+		return merchantUUID, itemUUID, false
+	}
+	return merchantUUID, itemUUID, true
+}
+
+// writeSoftDeleteResult answers a delete or restore, nothing changed means
+// the row does not exist or is already in the requested state.
+func writeSoftDeleteResult(c *gin.Context, affected int64, err error, notFound string) {
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+	if affected == 0 {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   notFound,
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
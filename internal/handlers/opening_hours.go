@@ -0,0 +1,406 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// parseClock converts "HH:MM" to minutes since midnight.
+func parseClock(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func clockToTime(minutes int) pgtype.Time {
+	return pgtype.Time{Microseconds: int64(minutes) * int64(time.Minute/time.Microsecond), Valid: true}
+}
+
+func timeToClock(t pgtype.Time) int {
+	return int(t.Microseconds / int64(time.Minute/time.Microsecond))
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// loadOpeningHours builds the schedule of every merchant in timeZones with one
+// statement for the weekly hours and one for holidays from since onwards.
+func loadOpeningHours(ctx context.Context, q *db.Queries, timeZones map[pgtype.UUID]string, since time.Time) (map[pgtype.UUID]shared.OpeningHours, error) {
+	ids := make([]pgtype.UUID, 0, len(timeZones))
+	for id := range timeZones {
+		ids = append(ids, id)
+	}
+
+	rows, err := q.GetMerchantOpeningHours(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	// Start a day early since holidays are local dates
+	holidayRows, err := q.GetMerchantHolidays(ctx, db.GetMerchantHolidaysParams{
+		MerchantIds: ids,
+		Since:       pgtype.Date{Time: since.UTC().AddDate(0, 0, -1), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make(map[pgtype.UUID][]shared.OpeningWindow, len(ids))
+	for _, r := range rows {
+		windows[r.MerchantID] = append(windows[r.MerchantID], shared.OpeningWindow{
+			Weekday: time.Weekday(r.DayOfWeek),
+			Opens:   timeToClock(r.OpensAt),
+			Closes:  timeToClock(r.ClosesAt),
+		})
+	}
+	holidays := make(map[pgtype.UUID][]string, len(ids))
+	for _, r := range holidayRows {
+		holidays[r.MerchantID] = append(holidays[r.MerchantID], r.HolidayDate.Time.Format(shared.DateLayout))
+	}
+
+	hours := make(map[pgtype.UUID]shared.OpeningHours, len(ids))
+	for id, timeZone := range timeZones {
+		hours[id] = shared.NewOpeningHours(timeZone, windows[id], holidays[id])
+	}
+	return hours, nil
+}
+
+// nextOpening formats when a closed merchant opens next in its own time zone.
+// It is nil when there is no opening within shared.OpeningSearchDays.
+func nextOpening(hours shared.OpeningHours, t time.Time) *string {
+	next, ok := hours.NextOpen(t)
+	if !ok {
+		return nil
+	}
+	formatted := next.In(hours.Location).Format(shared.ISO8601WithNanoseconds)
+	return &formatted
+}
+
+// findMerchantTimeZone resolves the merchantId path parameter, writing the
+// error response and returning false when it is malformed or unknown.
+func findMerchantTimeZone(c *gin.Context, queries *db.Queries) (pgtype.UUID, string, bool) {
+	var merchantUUID pgtype.UUID
+	if err := merchantUUID.Scan(c.Param("merchantId")); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid merchant ID format",
+			Code:    http.StatusBadRequest,
+		})
+		return pgtype.UUID{}, "", false
+	}
+
+	timeZone, err := queries.GetMerchantTimeZone(c.Request.Context(), merchantUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Merchant not found",
+			Code:    http.StatusNotFound,
+		})
+		return pgtype.UUID{}, "", false
+	}
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return pgtype.UUID{}, "", false
+	}
+
+	return merchantUUID, timeZone, true
+}
+
+func (h *MerchantHandler) GetOpeningHours(c *gin.Context) {
+	queries := db.New(h.pool)
+	ctx := context.Background()
+
+	merchantUUID, timeZone, ok := findMerchantTimeZone(c, queries)
+	if !ok {
+		return
+	}
+
+	rows, err := queries.GetMerchantOpeningHours(ctx, []pgtype.UUID{merchantUUID})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	hours := make([]dto.OpeningHoursWindow, 0, len(rows))
+	for _, r := range rows {
+		hours = append(hours, dto.OpeningHoursWindow{
+			Day:      strings.ToLower(time.Weekday(r.DayOfWeek).String()),
+			OpensAt:  formatClock(timeToClock(r.OpensAt)),
+			ClosesAt: formatClock(timeToClock(r.ClosesAt)),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.OpeningHoursResponse{
+		TimeZone: timeZone,
+		Hours:    hours,
+	})
+}
+
+func (h *MerchantHandler) UpdateOpeningHours(c *gin.Context) {
+	var payload dto.OpeningHoursRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: please make sure you have provided a time zone and valid opening hours",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if _, err := time.LoadLocation(payload.TimeZone); err != nil || payload.TimeZone == "Local" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid time zone. Must be an IANA name such as Asia/Jakarta",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	windows := make([]db.CreateMerchantOpeningHoursParams, 0, len(payload.Hours))
+	seen := make(map[[2]int]bool, len(payload.Hours))
+	for i, w := range payload.Hours {
+		day, dayOK := weekdayNames[strings.ToLower(w.Day)]
+		opens, opensOK := parseClock(w.OpensAt)
+		closes, closesOK := parseClock(w.ClosesAt)
+		if !dayOK || !opensOK || !closesOK || opens == closes {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid opening hours at hours[%d]. Day must be a weekday name and times HH:MM with different opening and closing times", i),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		key := [2]int{int(day), opens}
+		if seen[key] {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Duplicate opening hours at hours[%d]", i),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		seen[key] = true
+
+		windows = append(windows, db.CreateMerchantOpeningHoursParams{
+			DayOfWeek: int16(day),
+			OpensAt:   clockToTime(opens),
+			ClosesAt:  clockToTime(closes),
+		})
+	}
+
+	ctx := context.Background()
+	merchantUUID, _, ok := findMerchantTimeZone(c, db.New(h.pool))
+	if !ok {
+		return
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to start transaction",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := db.New(tx)
+
+	err = queries.UpdateMerchantTimeZone(ctx, db.UpdateMerchantTimeZoneParams{
+		ID:       merchantUUID,
+		TimeZone: payload.TimeZone,
+	})
+	if err == nil {
+		err = queries.DeleteMerchantOpeningHours(ctx, merchantUUID)
+	}
+	for _, w := range windows {
+		if err != nil {
+			break
+		}
+		w.MerchantID = merchantUUID
+		err = queries.CreateMerchantOpeningHours(ctx, w)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	hours := payload.Hours
+	if hours == nil {
+		hours = []dto.OpeningHoursWindow{}
+	}
+	c.JSON(http.StatusOK, dto.OpeningHoursResponse{
+		TimeZone: payload.TimeZone,
+		Hours:    hours,
+	})
+}
+
+func (h *MerchantHandler) GetHolidays(c *gin.Context) {
+	queries := db.New(h.pool)
+	ctx := context.Background()
+
+	merchantUUID, _, ok := findMerchantTimeZone(c, queries)
+	if !ok {
+		return
+	}
+
+	rows, err := queries.GetMerchantHolidays(ctx, db.GetMerchantHolidaysParams{
+		MerchantIds: []pgtype.UUID{merchantUUID},
+		Since:       pgtype.Date{Time: time.Now().UTC().AddDate(0, 0, -1), Valid: true},
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	data := make([]dto.MerchantHolidayData, 0, len(rows))
+	for _, r := range rows {
+		data = append(data, dto.MerchantHolidayData{
+			Date: r.HolidayDate.Time.Format(shared.DateLayout),
+			Note: r.Note.String,
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.GetMerchantHolidaysResponse{Data: data})
+}
+
+func (h *MerchantHandler) UpsertHoliday(c *gin.Context) {
+	var payload dto.MerchantHolidayRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: note must be at most 100 characters",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	date, err := time.Parse(shared.DateLayout, c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid date. Must be formatted as YYYY-MM-DD",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	queries := db.New(h.pool)
+	ctx := context.Background()
+
+	merchantUUID, _, ok := findMerchantTimeZone(c, queries)
+	if !ok {
+		return
+	}
+
+	note := strings.TrimSpace(payload.Note)
+	err = queries.UpsertMerchantHoliday(ctx, db.UpsertMerchantHolidayParams{
+		MerchantID:  merchantUUID,
+		HolidayDate: pgtype.Date{Time: date, Valid: true},
+		Note:        pgtype.Text{String: note, Valid: note != ""},
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MerchantHolidayData{
+		Date: date.Format(shared.DateLayout),
+		Note: note,
+	})
+}
+
+func (h *MerchantHandler) DeleteHoliday(c *gin.Context) {
+	date, err := time.Parse(shared.DateLayout, c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid date. Must be formatted as YYYY-MM-DD",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	queries := db.New(h.pool)
+	ctx := context.Background()
+
+	merchantUUID, _, ok := findMerchantTimeZone(c, queries)
+	if !ok {
+		return
+	}
+
+	deleted, err := queries.DeleteMerchantHoliday(ctx, db.DeleteMerchantHolidayParams{
+		MerchantID:  merchantUUID,
+		HolidayDate: pgtype.Date{Time: date, Valid: true},
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Holiday not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,28 +1,45 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/config"
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/payment"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type OrderHandler struct {
-	Q *db.Queries
+	Q        *db.Queries
+	pool     *pgxpool.Pool
+	cfg      *config.EstimateConfig
+	orderCfg *config.OrderConfig
+	payments payment.PaymentProvider
 }
 
-func NewOrderHandler(pool *pgxpool.Pool) *OrderHandler {
+func NewOrderHandler(pool *pgxpool.Pool, cfg *config.EstimateConfig, orderCfg *config.OrderConfig, payments payment.PaymentProvider) *OrderHandler {
 	q := db.New(pool)
-	return &OrderHandler{Q: q}
+	return &OrderHandler{Q: q, pool: pool, cfg: cfg, orderCfg: orderCfg, payments: payments}
 }
 
+// maxIdempotencyKeyLength bounds the Idempotency-Key header
+const maxIdempotencyKeyLength = 255
+
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	var req dto.CreateOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -56,6 +73,23 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	// A retried request with a known Idempotency-Key gets the original order back
+	idempotencyKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Idempotency-Key is too long",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	requestHash := hashCreateOrderRequest(req)
+	if idempotencyKey != "" {
+		if h.replayIdempotentOrder(c, user.ID, idempotencyKey, requestHash) {
+			return
+		}
+	}
+
 	// Validate that the calculated estimate exists and belongs to the user
 	estimateUUID, err := uuid.Parse(req.CalculatedEstimateID)
 	if err != nil {
@@ -78,11 +112,119 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	// Create the order
-	orderID, err := h.Q.CreateOrder(c, db.CreateOrderParams{
+	if estimate.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+			Success: false,
+			Error:   "Calculated estimate belongs to another user",
+			Code:    http.StatusForbidden,
+			Reason:  dto.ReasonEstimateNotOwned,
+		})
+		return
+	}
+
+	if time.Now().After(estimate.ExpiresAt.Time) {
+		c.JSON(http.StatusGone, dto.ErrorResponse{
+			Success: false,
+			Error:   "Calculated estimate has expired",
+			Code:    http.StatusGone,
+			Reason:  dto.ReasonEstimateExpired,
+		})
+		return
+	}
+
+	// Re-price the estimate against the current catalog
+	var estimateData dto.EstimateData
+	if err := json.Unmarshal(estimate.EstimateData, &estimateData); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to read estimate data",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	quote, err := quoteEstimate(c, h.Q, h.cfg, estimateData.EstimateRequest, true)
+	if err != nil {
+		writeEstimateError(c, err)
+		return
+	}
+
+	if int32(quote.TotalPrice) != estimate.TotalPrice {
+		c.JSON(http.StatusConflict, dto.PriceChangedResponse{
+			ErrorResponse: dto.ErrorResponse{
+				Success: false,
+				Error:   "Prices have changed since the estimate was calculated",
+				Code:    http.StatusConflict,
+				Reason:  dto.ReasonPriceChanged,
+			},
+			Data: dto.PriceChangedData{
+				PreviousTotalPrice: float64(estimate.TotalPrice),
+				Subtotal:           quote.Subtotal,
+				Fees:               quote.Fees,
+				TotalPrice:         quote.TotalPrice,
+			},
+		})
+		return
+	}
+
+	tx, err := h.pool.Begin(c)
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+	defer tx.Rollback(c)
+	qtx := h.Q.WithTx(tx)
+
+	// Orders paid through the provider are placed once the payment is captured
+	paymentMethod := req.PaymentMethod
+	if paymentMethod == "" {
+		paymentMethod = dto.PaymentMethodWallet
+	}
+	status := db.OrderStatusPlaced
+	if paymentMethod != dto.PaymentMethodWallet {
+		status = db.OrderStatusPendingPayment
+	}
+
+	// Create the order, the unique index on calculated_estimate_id makes every
+	// estimate single use
+	orderID, err := qtx.CreateOrder(c, db.CreateOrderParams{
 		UserID:               user.ID,
 		CalculatedEstimateID: estimate.ID,
+		Status:               status,
 	})
+	if err != nil {
+		if shared.IsUniqueViolation(err, "idx_orders_calculated_estimate_id") {
+			// A concurrent request with the same Idempotency-Key ordered the
+			// estimate first, its order is the answer. The insert only fails
+			// once that request committed, and its key with it.
+			if idempotencyKey != "" {
+				tx.Rollback(c)
+				if h.replayIdempotentOrder(c, user.ID, idempotencyKey, requestHash) {
+					return
+				}
+			}
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Success: false,
+				Error:   "Calculated estimate has already been ordered",
+				Code:    http.StatusConflict,
+				Reason:  dto.ReasonEstimateUsed,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create order",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	err = recordOrderStatus(c, qtx, orderID, db.NullOrderStatus{}, status, userActor(user))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Success: false,
@@ -92,9 +234,262 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	// One part per merchant, in route order
+	merchantIDs := make([]pgtype.UUID, 0, len(quote.Timeline.Stops))
+	subtotals := make([]int32, 0, len(quote.Timeline.Stops))
+	for _, stop := range quote.Timeline.Stops {
+		merchantIDs = append(merchantIDs, parseUUID(stop.MerchantID))
+		subtotals = append(subtotals, int32(stop.Subtotal))
+	}
+	err = qtx.CreateOrderMerchants(c, db.CreateOrderMerchantsParams{
+		OrderID:     orderID,
+		MerchantIds: merchantIDs,
+		Subtotals:   subtotals,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create order",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// The items of each part, in the order they were requested, and the
+	// options chosen for them
+	var itemMerchantIDs, itemIDs, optionMerchantIDs, optionIDs []pgtype.UUID
+	var quantities, positions, optionItemPositions, optionPositions []int32
+	for _, order := range estimateData.EstimateRequest.Orders {
+		for i, item := range order.Items {
+			itemMerchantIDs = append(itemMerchantIDs, parseUUID(order.MerchantId))
+			itemIDs = append(itemIDs, parseUUID(item.ItemId))
+			quantities = append(quantities, int32(item.Quantity))
+			positions = append(positions, int32(i))
+			for k, option := range item.Options {
+				optionMerchantIDs = append(optionMerchantIDs, parseUUID(order.MerchantId))
+				optionItemPositions = append(optionItemPositions, int32(i))
+				optionPositions = append(optionPositions, int32(k))
+				optionIDs = append(optionIDs, parseUUID(option))
+			}
+		}
+	}
+	err = qtx.CreateOrderItems(c, db.CreateOrderItemsParams{
+		OrderID:     orderID,
+		MerchantIds: itemMerchantIDs,
+		ItemIds:     itemIDs,
+		Quantities:  quantities,
+		Positions:   positions,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create order",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if len(optionIDs) > 0 {
+		err = qtx.CreateOrderItemOptions(c, db.CreateOrderItemOptionsParams{
+			OrderID:       orderID,
+			MerchantIds:   optionMerchantIDs,
+			ItemPositions: optionItemPositions,
+			Positions:     optionPositions,
+			OptionIds:     optionIDs,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Success: false,
+				Error:   "Failed to create order",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	}
+
+	// Availability was checked by the quote, reserving inside the transaction
+	// catches items sold out by a concurrent order
+	if err := reserveOrderStock(c, qtx, orderID, estimateData.EstimateRequest); err != nil {
+		if errors.Is(err, errItemsUnavailable) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Success: false,
+				Error:   "One or more items are no longer available",
+				Code:    http.StatusConflict,
+				Reason:  dto.ReasonItemUnavailable,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reserve stock",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	var pendingPayment db.Payment
+	if paymentMethod == dto.PaymentMethodWallet {
+		// Paid from the wallet inside the order's transaction, a failed order
+		// is never charged
+		if err := debitOrder(c, qtx, user.ID, orderID, int64(estimate.TotalPrice)); err != nil {
+			var balanceErr *InsufficientBalanceError
+			if errors.As(err, &balanceErr) {
+				writeInsufficientBalance(c, balanceErr)
+				return
+			}
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Success: false,
+				Error:   "Failed to charge the wallet",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+
+		// Without a free courier the order waits for the next one to become
+		// available
+		if err := qtx.AssignNearestCourier(c, orderID); err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Success: false,
+				Error:   "Failed to assign a courier",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	} else {
+		pendingPayment, err = createOrderPayment(c, qtx, h.payments, orderID, paymentMethod, int64(estimate.TotalPrice))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Success: false,
+				Error:   "Failed to create the payment",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	}
+
+	if idempotencyKey != "" {
+		err = qtx.CreateOrderIdempotencyKey(c, db.CreateOrderIdempotencyKeyParams{
+			UserID:         user.ID,
+			IdempotencyKey: idempotencyKey,
+			RequestHash:    requestHash,
+			OrderID:        orderID,
+		})
+		if err != nil {
+			// A concurrent request with the same key won the race
+			if shared.IsUniqueViolation(err, "order_idempotency_keys_pkey") {
+				tx.Rollback(c)
+				h.replayIdempotentOrder(c, user.ID, idempotencyKey, requestHash)
+				return
+			}
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Success: false,
+				Error:   "Failed to create order",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	}
+
+	if err := tx.Commit(c); err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	var orderPayment *dto.OrderPayment
+	if pendingPayment.ID.Valid {
+		orderPayment, err = startOrderPayment(c, h.Q, h.payments, pendingPayment)
+		if err != nil {
+			writePaymentStartError(c, orderID)
+			return
+		}
+	}
+
 	c.JSON(http.StatusCreated, dto.CreateOrderResponse{
 		OrderID: orderID.String(),
+		Payment: orderPayment,
+	})
+}
+
+// writePaymentStartError answers an order whose provider intent could not be
+// created. The order exists and waits for its payment.
+func writePaymentStartError(c *gin.Context, orderID pgtype.UUID) {
+	c.JSON(http.StatusBadGateway, dto.ErrorResponse{
+		Success: false,
+		Error:   fmt.Sprintf("Failed to start the payment of order %s, retry with the same Idempotency-Key", orderID.String()),
+		Code:    http.StatusBadGateway,
+	})
+}
+
+// hashCreateOrderRequest fingerprints the request body stored with an
+// Idempotency-Key. The default payment method is left out so keys stored
+// before payment methods existed still match.
+func hashCreateOrderRequest(req dto.CreateOrderRequest) string {
+	body := strings.ToLower(req.CalculatedEstimateID)
+	if req.PaymentMethod != "" && req.PaymentMethod != dto.PaymentMethodWallet {
+		body += "|" + req.PaymentMethod
+	}
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// replayIdempotentOrder answers a request whose Idempotency-Key was already
+// used. It returns false when the key is unknown and the request should run.
+func (h *OrderHandler) replayIdempotentOrder(c *gin.Context, userID pgtype.UUID, key, requestHash string) bool {
+	stored, err := h.Q.GetOrderIdempotencyKey(c, db.GetOrderIdempotencyKeyParams{
+		UserID:         userID,
+		IdempotencyKey: key,
 	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return true
+	}
+
+	if stored.RequestHash != requestHash {
+		c.JSON(http.StatusUnprocessableEntity, dto.ErrorResponse{
+			Success: false,
+			Error:   "Idempotency-Key was already used with a different request",
+			Code:    http.StatusUnprocessableEntity,
+			Reason:  dto.ReasonIdempotencyReuse,
+		})
+		return true
+	}
+
+	response := dto.CreateOrderResponse{OrderID: stored.OrderID.String()}
+	// The client still needs the payment to complete it, its intent is
+	// created now when the original request failed to
+	storedPayment, err := h.Q.GetOrderPayment(c, stored.OrderID)
+	if err == nil && !storedPayment.IntentID.Valid && storedPayment.Status == db.PaymentStatusPending {
+		response.Payment, err = startOrderPayment(c, h.Q, h.payments, storedPayment)
+		if err != nil {
+			writePaymentStartError(c, stored.OrderID)
+			return true
+		}
+	} else if err == nil {
+		response.Payment = toOrderPayment(storedPayment)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return true
+	}
+
+	c.JSON(http.StatusCreated, response)
+	return true
 }
 
 func (h *OrderHandler) GetOrders(c *gin.Context) {
@@ -137,8 +532,46 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 		params.Offset = 0
 	}
 
-	// Get total count for pagination metadata
-	totalCount, err := h.Q.GetOrdersCountByUserID(c, user.ID)
+	createdAt := "desc"
+	if params.CreatedAt != nil && *params.CreatedAt == "asc" {
+		createdAt = "asc"
+	}
+
+	var from, to string
+	if params.From != nil {
+		from = *params.From
+	}
+	if params.To != nil {
+		to = *params.To
+	}
+	createdFrom, errFrom := parseDateFilter(from, false)
+	createdTo, errTo := parseDateFilter(to, true)
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid date filter. Use RFC 3339 timestamps or YYYY-MM-DD dates",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	filters := db.CountUserOrdersParams{
+		UserID:      user.ID,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+	}
+	if params.MerchantID != nil && *params.MerchantID != "" {
+		filters.MerchantID = pgtype.Text{String: *params.MerchantID, Valid: true}
+	}
+	if params.MerchantCategory != nil && *params.MerchantCategory != "" {
+		filters.MerchantCategory = pgtype.Text{String: *params.MerchantCategory, Valid: true}
+	}
+	if params.Name != nil && *params.Name != "" {
+		filters.Name = pgtype.Text{String: *params.Name, Valid: true}
+	}
+
+	// Get total count of matching orders for pagination metadata
+	totalCount, err := h.Q.CountUserOrders(c, filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Success: false,
@@ -148,11 +581,16 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 		return
 	}
 
-	// Get orders from database (get more than needed for filtering)
-	orders, err := h.Q.GetOrdersByUserID(c, db.GetOrdersByUserIDParams{
-		Column1: user.ID,
-		Limit:   int32(params.Limit * 2), // Get more to account for filtering
-		Offset:  0,                       // Start from beginning, we'll apply offset after filtering
+	orders, err := h.Q.GetUserOrders(c, db.GetUserOrdersParams{
+		UserID:           filters.UserID,
+		MerchantID:       filters.MerchantID,
+		MerchantCategory: filters.MerchantCategory,
+		Name:             filters.Name,
+		CreatedFrom:      filters.CreatedFrom,
+		CreatedTo:        filters.CreatedTo,
+		CreatedAt:        createdAt,
+		OffsetVal:        int32(params.Offset),
+		LimitVal:         int32(params.Limit),
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
@@ -163,237 +601,157 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 		return
 	}
 
-	// Process the orders and build response
-	response, totalFiltered := h.buildOrdersResponse(c, orders, params, int(totalCount))
+	response, err := h.buildOrdersResponse(c, orders)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get orders",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
 
 	// Add pagination metadata to response headers
-	c.Header("X-Total-Count", fmt.Sprintf("%d", totalFiltered))
+	c.Header("X-Total-Count", fmt.Sprintf("%d", totalCount))
 	c.Header("X-Limit", fmt.Sprintf("%d", params.Limit))
 	c.Header("X-Offset", fmt.Sprintf("%d", params.Offset))
+	if link := paginationLinks(c.Request.URL, params.Limit, params.Offset, int(totalCount)); link != "" {
+		c.Header("Link", link)
+	}
 
+	if params.Meta {
+		c.JSON(http.StatusOK, dto.GetOrdersResponse{
+			Data: response,
+			Meta: dto.GetOrdersResponseMeta{
+				Limit:  params.Limit,
+				Offset: params.Offset,
+				Total:  int(totalCount),
+			},
+		})
+		return
+	}
 	c.JSON(http.StatusOK, response)
 }
 
-func (h *OrderHandler) buildOrdersResponse(c *gin.Context, orders []db.GetOrdersByUserIDRow, params dto.GetOrdersParams, totalCount int) (dto.OrderHistoryResponse, int) {
-	var allFilteredOrders []dto.OrderHistory // To track all filtered orders for accurate total
-
-	// Handle empty orders case
-	if len(orders) == 0 {
-		return dto.OrderHistoryResponse{}, 0
-	}
-
-	// First pass: Get all orders that match the filters
-	for _, order := range orders {
-		orderIDStr := order.ID.String()
-
-		// Parse estimate data
-		var estimateRequest dto.EstimateRequest
-		if err := json.Unmarshal(order.EstimateData, &estimateRequest); err != nil {
-			continue
-		}
-
-		// Apply filters
-		if !h.matchesFilters(c, estimateRequest, params) {
-			continue
-		}
-
-		// Extract order details from estimate data
-		orderDetails, err := h.extractOrderDetails(c, estimateRequest)
-		if err != nil {
-			continue
-		}
-
-		orderHistory := dto.OrderHistory{
-			OrderID: orderIDStr,
-			Orders:  orderDetails,
-		}
-
-		allFilteredOrders = append(allFilteredOrders, orderHistory)
+// paginationLinks builds an RFC 8288 Link header value with the next and prev
+// pages of u. It is empty when there is neither.
+func paginationLinks(u *url.URL, limit, offset, total int) string {
+	page := func(offset int, rel string) string {
+		query := u.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset))
+		target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+		return fmt.Sprintf("<%s>; rel=\"%s\"", target.String(), rel)
 	}
 
-	totalFiltered := len(allFilteredOrders)
-
-	// Apply pagination to get the current page
-	start := params.Offset
-	end := start + params.Limit
-
-	// Ensure we don't go beyond the available orders
-	if start > totalFiltered {
-		start = totalFiltered
+	var links []string
+	if offset+limit < total {
+		links = append(links, page(offset+limit, "next"))
 	}
-	if end > totalFiltered {
-		end = totalFiltered
+	if offset > 0 {
+		links = append(links, page(max(offset-limit, 0), "prev"))
 	}
-
-	filteredData := allFilteredOrders[start:end]
-
-	return dto.OrderHistoryResponse(filteredData), totalFiltered
+	return strings.Join(links, ", ")
 }
 
-func (h *OrderHandler) matchesFilters(c *gin.Context, estimateRequest dto.EstimateRequest, params dto.GetOrdersParams) bool {
-	// Apply merchant ID filter
-	if params.MerchantID != nil && *params.MerchantID != "" {
-		found := false
-		for _, order := range estimateRequest.Orders {
-			if order.MerchantId == *params.MerchantID {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
+// buildOrdersResponse loads the merchants, items and chosen item options of a
+// page of orders with one query each. They are rendered from the details
+// copied when the orders were placed, never from the current catalog.
+func (h *OrderHandler) buildOrdersResponse(c *gin.Context, orders []db.GetUserOrdersRow) (dto.OrderHistoryResponse, error) {
+	response := dto.OrderHistoryResponse{}
+	if len(orders) == 0 {
+		return response, nil
 	}
 
-	// Apply name filter
-	if params.Name != nil && *params.Name != "" {
-		// We need to check if merchant name or item name contains the search string
-		nameMatch := false
-
-		// Check each order for matching merchant or item name
-		for _, order := range estimateRequest.Orders {
-			// Get merchant details
-			merchantUUID, err := uuid.Parse(order.MerchantId)
-			if err != nil {
-				continue
-			}
-
-			merchantPgUUID := pgtype.UUID{Bytes: merchantUUID, Valid: true}
-			merchant, err := h.Q.GetMerchantDetailsByID(c, merchantPgUUID)
-			if err != nil {
-				continue
-			}
-
-			// Check merchant name (case insensitive)
-			if strings.Contains(strings.ToLower(merchant.Name), strings.ToLower(*params.Name)) {
-				nameMatch = true
-				break
-			}
-
-			// Check item names
-			for _, item := range order.Items {
-				itemUUID, err := uuid.Parse(item.ItemId)
-				if err != nil {
-					continue
-				}
-
-				itemPgUUID := pgtype.UUID{Bytes: itemUUID, Valid: true}
-				merchantItem, err := h.Q.GetMerchantItemByID(c, itemPgUUID)
-				if err != nil {
-					continue
-				}
-
-				// Check item name (case insensitive)
-				if strings.Contains(strings.ToLower(merchantItem.Name), strings.ToLower(*params.Name)) {
-					nameMatch = true
-					break
-				}
-			}
-
-			if nameMatch {
-				break
-			}
-		}
-
-		if !nameMatch {
-			return false
-		}
+	orderIDs := make([]pgtype.UUID, len(orders))
+	for i, order := range orders {
+		orderIDs[i] = order.ID
 	}
 
-	// Apply merchant category filter
-	if params.MerchantCategory != nil && *params.MerchantCategory != "" {
-		categoryMatch := false
-
-		// Check each order for matching merchant category
-		for _, order := range estimateRequest.Orders {
-			// Get merchant details
-			merchantUUID, err := uuid.Parse(order.MerchantId)
-			if err != nil {
-				continue
-			}
-
-			merchantPgUUID := pgtype.UUID{Bytes: merchantUUID, Valid: true}
-			merchant, err := h.Q.GetMerchantDetailsByID(c, merchantPgUUID)
-			if err != nil {
-				continue
-			}
-
-			// Check merchant category
-			if string(merchant.MerchantCategory) == *params.MerchantCategory {
-				categoryMatch = true
-				break
-			}
-		}
-
-		if !categoryMatch {
-			return false
-		}
+	merchants, err := h.Q.GetOrderHistoryMerchants(c, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	items, err := h.Q.GetOrderHistoryItems(c, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	options, err := h.Q.GetOrderHistoryItemOptions(c, orderIDs)
+	if err != nil {
+		return nil, err
 	}
 
-	return true
-}
-
-func (h *OrderHandler) extractOrderDetails(c *gin.Context, estimateRequest dto.EstimateRequest) ([]dto.OrderDetail, error) {
-	var orderDetails []dto.OrderDetail
-
-	for _, order := range estimateRequest.Orders {
-		// Get merchant details from database
-		merchantUUID, err := uuid.Parse(order.MerchantId)
-		if err != nil {
-			continue // Skip invalid merchant ID
-		}
-
-		merchantPgUUID := pgtype.UUID{Bytes: merchantUUID, Valid: true}
-		merchant, err := h.Q.GetMerchantDetailsByID(c, merchantPgUUID)
-		if err != nil {
-			continue // Skip if merchant not found
-		}
-
-		// Get items details from database
-		var orderItems []dto.OrderItem
-		for _, item := range order.Items {
-			itemUUID, err := uuid.Parse(item.ItemId)
-			if err != nil {
-				continue // Skip invalid item ID
-			}
+	type itemKey struct {
+		order, merchant pgtype.UUID
+		position        int32
+	}
+	itemOptions := make(map[itemKey][]dto.OrderItemOption)
+	for _, o := range options {
+		key := itemKey{o.OrderID, o.MerchantID, o.ItemPosition}
+		itemOptions[key] = append(itemOptions[key], dto.OrderItemOption{
+			OptionID:   o.OptionID.String(),
+			GroupName:  o.GroupName,
+			Name:       o.Name,
+			PriceDelta: int(o.PriceDelta),
+		})
+	}
 
-			itemPgUUID := pgtype.UUID{Bytes: itemUUID, Valid: true}
-			merchantItem, err := h.Q.GetMerchantItemByID(c, itemPgUUID)
-			if err != nil {
-				continue // Skip if item not found
-			}
+	type partKey struct{ order, merchant pgtype.UUID }
+	partItems := make(map[partKey][]dto.OrderItem)
+	for _, item := range items {
+		key := partKey{item.OrderID, item.MerchantID}
+		partItems[key] = append(partItems[key], dto.OrderItem{
+			ItemID:          item.ItemID.String(),
+			Name:            item.Name,
+			ProductCategory: string(item.ProductCategory),
+			Price:           int(item.UnitPrice),
+			Quantity:        int(item.Quantity),
+			ImageURL:        item.ImageUrl,
+			CreatedAt:       item.ItemCreatedAt.Time.Format("2006-01-02T15:04:05.000000000Z07:00"),
+			Options:         itemOptions[itemKey{item.OrderID, item.MerchantID, item.Position}],
+		})
+	}
 
-			orderItem := dto.OrderItem{
-				ItemID:          item.ItemId,
-				Name:            merchantItem.Name,
-				ProductCategory: string(merchantItem.ProductCategory),
-				Price:           int(merchantItem.Price),
-				Quantity:        item.Quantity,
-				ImageURL:        merchantItem.ImageUrl,
-				CreatedAt:       merchantItem.CreatedAt.Time.Format("2006-01-02T15:04:05.000000000Z07:00"),
-			}
-			orderItems = append(orderItems, orderItem)
+	details := make(map[pgtype.UUID][]dto.OrderDetail)
+	for _, m := range merchants {
+		orderItems := partItems[partKey{m.OrderID, m.MerchantID}]
+		if orderItems == nil {
+			orderItems = []dto.OrderItem{}
 		}
-
-		lat64, _ := merchant.Lat.(float64)
-		long64, _ := merchant.Long.(float64)
-
-		orderDetail := dto.OrderDetail{
+		details[m.OrderID] = append(details[m.OrderID], dto.OrderDetail{
 			Merchant: dto.OrderMerchant{
-				MerchantID:       order.MerchantId,
-				Name:             merchant.Name,
-				MerchantCategory: string(merchant.MerchantCategory),
-				ImageURL:         merchant.ImageUrl,
+				MerchantID:       m.MerchantID.String(),
+				Name:             m.Name,
+				MerchantCategory: string(m.MerchantCategory),
+				ImageURL:         m.ImageUrl,
 				Location: dto.OrderLocation{
-					Lat:  lat64,
-					Long: long64,
+					Lat:  m.Lat,
+					Long: m.Long,
 				},
-				CreatedAt: merchant.CreatedAt.Time.Format("2006-01-02T15:04:05.000000000Z07:00"),
+				CreatedAt: m.MerchantCreatedAt.Time.Format("2006-01-02T15:04:05.000000000Z07:00"),
 			},
 			Items: orderItems,
+		})
+	}
+
+	for _, order := range orders {
+		// The timeline is only kept in the estimate
+		var estimateData dto.EstimateData
+		if err := json.Unmarshal(order.EstimateData, &estimateData); err != nil {
+			return nil, err
+		}
+
+		orderDetails := details[order.ID]
+		if orderDetails == nil {
+			orderDetails = []dto.OrderDetail{}
 		}
-		orderDetails = append(orderDetails, orderDetail)
+		response = append(response, dto.OrderHistory{
+			OrderID:  order.ID.String(),
+			Status:   string(order.Status),
+			Orders:   orderDetails,
+			Timeline: estimateData.Timeline,
+		})
 	}
 
-	return orderDetails, nil
+	return response, nil
 }
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// cancellationFee applies the cancellation policy: nothing is kept until a
+// merchant has accepted its part of the order, feePercent of the total after.
+// feePercent is checked to be between 0 and 100 when the config loads.
+func cancellationFee(total int, feePercent int, accepted bool) int {
+	if !accepted {
+		return 0
+	}
+	return total * feePercent / 100
+}
+
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	// The body is optional, it only carries the reason
+	var req dto.CancelOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body: reason must be at most 255 characters",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	username, _ := c.Get("username")
+	user, err := h.Q.GetUserByUsername(c, username.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var orderID pgtype.UUID
+	if err := orderID.Scan(c.Param("orderId")); err != nil {
+		writeOrderStatusError(c, errOrderNotFound)
+		return
+	}
+
+	tx, err := h.pool.Begin(c)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	defer tx.Rollback(c)
+	qtx := h.Q.WithTx(tx)
+
+	// Other users' orders read as not found
+	order, err := qtx.GetUserOrderForCancellation(c, db.GetUserOrderForCancellationParams{
+		ID:     orderID,
+		UserID: user.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeOrderStatusError(c, errOrderNotFound)
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	if order.Status == db.OrderStatusPickedUp || order.Status == db.OrderStatusDelivered {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Success: false,
+			Error:   "Order has already been picked up and can no longer be cancelled",
+			Code:    http.StatusConflict,
+			Reason:  dto.ReasonCancellationNotAllowed,
+		})
+		return
+	}
+	// Caught before the cancellation is stored, which allows one per order
+	if !canTransitionOrder(order.Status, db.OrderStatusCancelled) {
+		writeOrderStatusError(c, &OrderTransitionError{From: order.Status, To: db.OrderStatusCancelled})
+		return
+	}
+
+	statuses, err := qtx.GetOrderMerchantStatuses(c, orderID)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	accepted := order.Status != db.OrderStatusPendingPayment && order.Status != db.OrderStatusPlaced
+	for _, s := range statuses {
+		accepted = accepted || s == db.OrderMerchantStatusAccepted || s == db.OrderMerchantStatusReady
+	}
+
+	total := int(order.TotalPrice)
+	fee := cancellationFee(total, h.orderCfg.CancellationFeePercent, accepted)
+	reason := strings.TrimSpace(req.Reason)
+	err = qtx.CreateOrderCancellation(c, db.CreateOrderCancellationParams{
+		OrderID:      orderID,
+		Reason:       pgtype.Text{String: reason, Valid: reason != ""},
+		Fee:          int32(fee),
+		RefundAmount: int32(total - fee),
+		CancelledBy:  user.ID,
+	})
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	// The refund reads refund_amount, so the cancellation is stored first
+	if err := transitionOrder(c, qtx, orderID, db.OrderStatusCancelled, userActor(user)); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	h.settleRefund(c, orderID)
+
+	c.JSON(http.StatusOK, dto.CancelOrderResponse{
+		OrderID:      orderID.String(),
+		Status:       string(db.OrderStatusCancelled),
+		Fee:          fee,
+		RefundAmount: total - fee,
+	})
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/config"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/events"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/middleware"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/net/websocket"
+)
+
+// eventHeartbeatInterval keeps idle streams from being closed by proxies
+const eventHeartbeatInterval = 15 * time.Second
+
+type EventHandler struct {
+	Q      *db.Queries
+	broker *events.Broker
+	cfg    *config.EventsConfig
+}
+
+func NewEventHandler(pool *pgxpool.Pool, broker *events.Broker, cfg *config.EventsConfig) *EventHandler {
+	q := db.New(pool)
+	return &EventHandler{Q: q, broker: broker, cfg: cfg}
+}
+
+// createOrderEvent stores an event for clients watching the order. It is
+// published once q's transaction commits.
+func createOrderEvent(ctx context.Context, q *db.Queries, orderID pgtype.UUID, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return q.CreateOrderEvent(ctx, db.CreateOrderEventParams{
+		OrderID: orderID,
+		Type:    eventType,
+		Data:    payload,
+	})
+}
+
+func toEvent(e db.OrderEvent) events.Event {
+	return events.Event{
+		ID:        e.ID,
+		OrderID:   e.OrderID.String(),
+		Type:      e.Type,
+		Data:      e.Data,
+		CreatedAt: e.CreatedAt.Time,
+	}
+}
+
+// CreateOrderEventsTicket issues the ticket a browser passes as ?ticket= to
+// open the event streams of an order, as EventSource and WebSocket cannot send
+// the Authorization header. Every connection attempt after it expired needs
+// a new ticket.
+func (h *EventHandler) CreateOrderEventsTicket(c *gin.Context) {
+	orderID, _, ok := h.authorizeOrderEvents(c, "")
+	if !ok {
+		return
+	}
+
+	username, _ := c.Get("username")
+	role, _ := c.Get("role")
+	ticket, expiresAt, err := middleware.GenerateStreamTicket(username.(string), role.(string), orderID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to issue a ticket",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.OrderEventsTicketResponse{
+		Ticket:    ticket,
+		ExpiresAt: expiresAt.Format(shared.ISO8601WithNanoseconds),
+	})
+}
+
+// StreamOrderEvents streams the events of an order as Server-Sent Events.
+// A client reconnecting with Last-Event-ID first gets the events it missed.
+func (h *EventHandler) StreamOrderEvents(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	orderID, lastID, ok := h.authorizeOrderEvents(c, lastEventID)
+	if !ok {
+		return
+	}
+
+	live, cancel := h.broker.Subscribe(orderID.String())
+	defer cancel()
+	missed, err := h.Q.GetOrderEventsSince(c, db.GetOrderEventsSinceParams{OrderID: orderID, ID: lastID})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(e events.Event) error {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+	ping := func() error {
+		if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+	// Flush the headers even when there is nothing to replay
+	c.Writer.Flush()
+
+	streamOrderEvents(c.Request.Context(), missed, live, lastID, send, ping)
+}
+
+// OrderEventsSocket streams the same events as StreamOrderEvents over a
+// WebSocket, one JSON message per event. Browsers cannot set headers on a
+// WebSocket, so the last seen event is passed as ?lastEventId=.
+func (h *EventHandler) OrderEventsSocket(c *gin.Context) {
+	orderID, lastID, ok := h.authorizeOrderEvents(c, c.Query("lastEventId"))
+	if !ok {
+		return
+	}
+
+	live, cancel := h.broker.Subscribe(orderID.String())
+	defer cancel()
+	missed, err := h.Q.GetOrderEventsSince(c, db.GetOrderEventsSinceParams{OrderID: orderID, ID: lastID})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	server := websocket.Server{
+		Handshake: h.checkSocketOrigin,
+		Handler: func(ws *websocket.Conn) {
+			ctx, stop := context.WithCancel(c.Request.Context())
+			defer stop()
+
+			// The client only ever closes the socket
+			go func() {
+				var discard string
+				for websocket.Message.Receive(ws, &discard) == nil {
+				}
+				stop()
+			}()
+
+			send := func(e events.Event) error { return websocket.JSON.Send(ws, e) }
+			ping := func() error { return websocket.JSON.Send(ws, gin.H{"type": "ping"}) }
+			streamOrderEvents(ctx, missed, live, lastID, send, ping)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkSocketOrigin refuses WebSockets opened by pages of other sites, which
+// could otherwise ride on a ticket or header they got hold of. Browsers always
+// send an Origin; other clients may leave it out.
+func (h *EventHandler) checkSocketOrigin(config *websocket.Config, req *http.Request) error {
+	origin, err := websocket.Origin(config, req)
+	if err != nil {
+		return err
+	}
+	config.Origin = origin
+	if origin == nil {
+		return nil
+	}
+
+	if strings.EqualFold(origin.Host, req.Host) {
+		return nil
+	}
+	if slices.Contains(h.cfg.AllowedOrigins, strings.ToLower(origin.Scheme+"://"+origin.Host)) {
+		return nil
+	}
+	return fmt.Errorf("websocket origin %s is not allowed", origin)
+}
+
+// streamOrderEvents sends the missed events and then live ones until ctx is
+// done, the subscription ends or sending fails. Live events already sent
+// during the replay are skipped.
+func streamOrderEvents(ctx context.Context, missed []db.OrderEvent, live <-chan events.Event, lastID int64, send func(events.Event) error, ping func() error) {
+	for _, e := range missed {
+		if err := send(toEvent(e)); err != nil {
+			return
+		}
+		lastID = e.ID
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-live:
+			if !ok {
+				return
+			}
+			if e.ID <= lastID {
+				continue
+			}
+			if err := send(e); err != nil {
+				return
+			}
+			lastID = e.ID
+		case <-heartbeat.C:
+			if err := ping(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// authorizeOrderEvents checks that the order belongs to the user and parses
+// the last event id the client saw. It writes the error response when ok is
+// false.
+func (h *EventHandler) authorizeOrderEvents(c *gin.Context, lastEventID string) (orderID pgtype.UUID, lastID int64, ok bool) {
+	username, _ := c.Get("username")
+	user, err := h.Q.GetUserByUsername(c, username.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return orderID, 0, false
+	}
+
+	if lastEventID != "" {
+		lastID, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || lastID < 0 {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   "Invalid Last-Event-ID",
+				Code:    http.StatusBadRequest,
+			})
+			return orderID, 0, false
+		}
+	}
+
+	// Other users' orders read as not found
+	notFound := dto.ErrorResponse{
+		Success: false,
+		Error:   "Order not found",
+		Code:    http.StatusNotFound,
+	}
+	if err := orderID.Scan(c.Param("orderId")); err != nil {
+		c.JSON(http.StatusNotFound, notFound)
+		return orderID, 0, false
+	}
+	ownerID, err := h.Q.GetOrderUserID(c, orderID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && ownerID != user.ID) {
+		c.JSON(http.StatusNotFound, notFound)
+		return orderID, 0, false
+	}
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return orderID, 0, false
+	}
+
+	return orderID, lastID, true
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/events"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// orderTransitions lists the statuses an order may move to from each status.
+// Delivered, cancelled and rejected orders are final. Orders paid through the
+// payment provider wait in pending_payment until the payment is captured,
+// only the system moves them on to placed.
+var orderTransitions = map[db.OrderStatus][]db.OrderStatus{
+	db.OrderStatusPendingPayment: {db.OrderStatusPlaced, db.OrderStatusCancelled},
+	db.OrderStatusPlaced:         {db.OrderStatusAccepted, db.OrderStatusRejected, db.OrderStatusCancelled},
+	db.OrderStatusAccepted:       {db.OrderStatusPreparing, db.OrderStatusCancelled},
+	db.OrderStatusPreparing:      {db.OrderStatusPickedUp, db.OrderStatusCancelled},
+	db.OrderStatusPickedUp:       {db.OrderStatusDelivered},
+}
+
+func canTransitionOrder(from, to db.OrderStatus) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+var errOrderNotFound = errors.New("order not found")
+
+// OrderTransitionError is returned when an order cannot move to the requested status.
+type OrderTransitionError struct {
+	From db.OrderStatus
+	To   db.OrderStatus
+}
+
+func (e *OrderTransitionError) Error() string {
+	return fmt.Sprintf("order cannot move from %s to %s", e.From, e.To)
+}
+
+// orderActor is who changed an order status. The zero value is the system.
+type orderActor struct {
+	ID   pgtype.UUID
+	Role db.NullUserRole
+}
+
+func userActor(user db.User) orderActor {
+	return orderActor{ID: user.ID, Role: db.NullUserRole{UserRole: user.Role, Valid: true}}
+}
+
+// transitionOrder moves an order to status to and records the change. q must
+// run inside a transaction, the order row stays locked until it ends. Stock
+// reserved by the order and its payment are given back when it is cancelled
+// or rejected, and its courier moves on to the next waiting order once it is
+// final.
+func transitionOrder(ctx context.Context, q *db.Queries, orderID pgtype.UUID, to db.OrderStatus, actor orderActor) error {
+	from, err := q.GetOrderStatusForUpdate(ctx, orderID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errOrderNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if !canTransitionOrder(from, to) {
+		return &OrderTransitionError{From: from, To: to}
+	}
+	// Placing an unpaid order would skip the payment and courier assignment
+	// of capturePayment
+	if from == db.OrderStatusPendingPayment && to == db.OrderStatusPlaced && actor.ID.Valid {
+		return &OrderTransitionError{From: from, To: to}
+	}
+
+	if err := q.UpdateOrderStatus(ctx, db.UpdateOrderStatusParams{ID: orderID, Status: to}); err != nil {
+		return err
+	}
+	if err := recordOrderStatus(ctx, q, orderID, db.NullOrderStatus{OrderStatus: from, Valid: true}, to, actor); err != nil {
+		return err
+	}
+
+	if isFinalOrderStatus(to) {
+		courierID, err := q.GetOrderCourierID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if courierID.Valid {
+			if err := q.AssignNearestOrder(ctx, courierID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if to == db.OrderStatusCancelled || to == db.OrderStatusRejected {
+		if err := refundOrder(ctx, q, orderID); err != nil {
+			return err
+		}
+		// The provider refund itself is sent by settlePaymentRefund once the
+		// transaction commits
+		if err := q.RequestOrderPaymentRefund(ctx, orderID); err != nil {
+			return err
+		}
+		return q.ReleaseOrderReservations(ctx, orderID)
+	}
+	return nil
+}
+
+// recordOrderStatus adds the change to the status history and publishes it
+// to clients watching the order.
+func recordOrderStatus(ctx context.Context, q *db.Queries, orderID pgtype.UUID, from db.NullOrderStatus, to db.OrderStatus, actor orderActor) error {
+	err := q.CreateOrderStatusHistory(ctx, db.CreateOrderStatusHistoryParams{
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+	})
+	if err != nil {
+		return err
+	}
+
+	data := dto.OrderStatusEventData{Status: string(to)}
+	if from.Valid {
+		previous := string(from.OrderStatus)
+		data.From = &previous
+	}
+	return createOrderEvent(ctx, q, orderID, events.TypeStatus, data)
+}
+
+// writeOrderStatusError maps a transitionOrder error to its HTTP response.
+func writeOrderStatusError(c *gin.Context, err error) {
+	var transitionErr *OrderTransitionError
+	var merchantTransitionErr *OrderMerchantTransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Order cannot move from %s to %s", transitionErr.From, transitionErr.To),
+			Code:    http.StatusConflict,
+			Reason:  dto.ReasonInvalidTransition,
+		})
+	case errors.As(err, &merchantTransitionErr):
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Success: false,
+			Error: fmt.Sprintf("Merchant order cannot move from %s to %s while the order is %s",
+				merchantTransitionErr.From, merchantTransitionErr.To, merchantTransitionErr.OrderStatus),
+			Code:   http.StatusConflict,
+			Reason: dto.ReasonInvalidTransition,
+		})
+	case errors.Is(err, errOrderNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Order not found",
+			Code:    http.StatusNotFound,
+		})
+	default:
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+	}
+}
+
+func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	var req dto.UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	status := db.OrderStatus(req.Status)
+	if _, known := orderTransitions[status]; !known && !isFinalOrderStatus(status) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid status. Must be one of: pending_payment, placed, accepted, preparing, picked_up, delivered, cancelled, rejected",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var orderID pgtype.UUID
+	if err := orderID.Scan(c.Param("orderId")); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Order not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	username, _ := c.Get("username")
+	admin, err := h.Q.GetAdminByUsername(c, username.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "Admin not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	tx, err := h.pool.Begin(c)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	if err := transitionOrder(c, h.Q.WithTx(tx), orderID, status, userActor(admin)); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if err := tx.Commit(c); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if status == db.OrderStatusCancelled || status == db.OrderStatusRejected {
+		h.settleRefund(c, orderID)
+	}
+
+	c.JSON(http.StatusOK, dto.UpdateOrderStatusResponse{
+		OrderID: orderID.String(),
+		Status:  req.Status,
+	})
+}
+
+func isFinalOrderStatus(status db.OrderStatus) bool {
+	return status == db.OrderStatusDelivered || status == db.OrderStatusCancelled || status == db.OrderStatusRejected
+}
@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/payment"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+type PaymentHandler struct {
+	Q        *db.Queries
+	pool     *pgxpool.Pool
+	provider payment.PaymentProvider
+}
+
+func NewPaymentHandler(pool *pgxpool.Pool, provider payment.PaymentProvider) *PaymentHandler {
+	q := db.New(pool)
+	return &PaymentHandler{Q: q, pool: pool, provider: provider}
+}
+
+// createOrderPayment records the provider payment of an order. q must run
+// inside the transaction creating the order; the provider intent is only
+// created by startOrderPayment once it committed.
+func createOrderPayment(ctx context.Context, q *db.Queries, provider payment.PaymentProvider, orderID pgtype.UUID, method string, amount int64) (db.Payment, error) {
+	return q.CreatePayment(ctx, db.CreatePaymentParams{
+		OrderID:  orderID,
+		Provider: provider.Name(),
+		Method:   method,
+		Amount:   int32(amount),
+	})
+}
+
+// startOrderPayment creates the provider intent of a committed payment, so a
+// rolled back order never leaves one behind. The order id is the intent's
+// reference. A payment whose intent could not be created is started again
+// when the order request is retried with its Idempotency-Key, or cancelled
+// with its order once it expires.
+func startOrderPayment(ctx context.Context, q *db.Queries, provider payment.PaymentProvider, p db.Payment) (*dto.OrderPayment, error) {
+	intent, err := provider.CreateIntent(ctx, int64(p.Amount), p.OrderID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	p.IntentID = pgtype.Text{String: intent.ID, Valid: true}
+	p.ClientSecret = pgtype.Text{String: intent.ClientSecret, Valid: true}
+	updated, err := q.SetPaymentIntent(ctx, db.SetPaymentIntentParams{
+		ID:           p.ID,
+		IntentID:     p.IntentID,
+		ClientSecret: p.ClientSecret,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		// A concurrent retry stored its intent first, or the order expired
+		stored, err := q.GetOrderPayment(ctx, p.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		return toOrderPayment(stored), nil
+	}
+	return toOrderPayment(p), nil
+}
+
+func toOrderPayment(p db.Payment) *dto.OrderPayment {
+	return &dto.OrderPayment{
+		Provider:     p.Provider,
+		Method:       p.Method,
+		IntentID:     p.IntentID.String,
+		ClientSecret: p.ClientSecret.String,
+		Amount:       int(p.Amount),
+		Status:       string(p.Status),
+	}
+}
+
+// settlePaymentRefund sends the refund owed on an order to the provider once
+// the cancellation committed. A failed refund stays refund_pending and is
+// tried again on the next webhook for the payment.
+func settlePaymentRefund(ctx context.Context, pool *pgxpool.Pool, q *db.Queries, provider payment.PaymentProvider, orderID pgtype.UUID) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := q.WithTx(tx)
+
+	p, err := qtx.GetPendingRefundForUpdate(ctx, orderID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if p.RefundAmount.Int32 > 0 {
+		if err := provider.Refund(ctx, p.IntentID.String, int64(p.RefundAmount.Int32)); err != nil {
+			return err
+		}
+	}
+	err = qtx.UpdatePaymentStatus(ctx, db.UpdatePaymentStatusParams{
+		ID:     p.ID,
+		Status: db.PaymentStatusRefunded,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// settleRefund refunds a cancelled or rejected order's provider payment. The
+// order change is already committed, so a failure is only logged.
+func (h *OrderHandler) settleRefund(ctx context.Context, orderID pgtype.UUID) {
+	if err := settlePaymentRefund(ctx, h.pool, h.Q, h.payments, orderID); err != nil {
+		log.Error().Err(err).Str("orderId", orderID.String()).Msg("failed to refund order payment")
+	}
+}
+
+// Webhook receives payment events from the provider. Events are applied once,
+// redeliveries and events for unknown payments are acknowledged and ignored.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	event, err := h.provider.VerifyWebhook(c.Request.Header, body)
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid webhook signature",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid webhook payload",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	h.applyEvent(c, event)
+}
+
+// SimulateFakePayment acts on a fake provider payment as the customer would
+// and delivers the signed webhook the provider sends for it. It only exists
+// with the fake provider.
+func (h *PaymentHandler) SimulateFakePayment(c *gin.Context) {
+	fake, ok := h.provider.(*payment.FakeProvider)
+	if !ok {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	eventType := "payment." + c.Param("event")
+	switch eventType {
+	case payment.EventAuthorized, payment.EventCaptured, payment.EventFailed, payment.EventRefunded:
+	default:
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid event. Must be one of: authorized, captured, failed, refunded",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	p, err := h.Q.GetPaymentByIntent(c, db.GetPaymentByIntentParams{
+		Provider: fake.Name(),
+		IntentID: pgtype.Text{String: c.Param("intentId"), Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Payment not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	header, body, err := fake.Webhook(eventType, p.IntentID.String, int64(p.Amount))
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	// Verified like any delivered webhook
+	event, err := h.provider.VerifyWebhook(header, body)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	h.applyEvent(c, event)
+}
+
+// applyEvent updates the payment and its order for a verified event. Orders
+// are placed once their payment is captured for its full amount and cancelled
+// when it fails.
+func (h *PaymentHandler) applyEvent(c *gin.Context, event payment.WebhookEvent) {
+	tx, err := h.pool.Begin(c)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	defer tx.Rollback(c)
+	qtx := h.Q.WithTx(tx)
+
+	received, err := qtx.CreatePaymentWebhookEvent(c, db.CreatePaymentWebhookEventParams{
+		Provider: h.provider.Name(),
+		EventID:  event.ID,
+		Type:     event.Type,
+	})
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if received == 0 {
+		c.JSON(http.StatusOK, dto.PaymentWebhookResponse{Received: true})
+		return
+	}
+
+	p, err := qtx.GetPaymentByIntentForUpdate(c, db.GetPaymentByIntentForUpdateParams{
+		Provider: h.provider.Name(),
+		IntentID: pgtype.Text{String: event.IntentID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		if err := tx.Commit(c); err != nil {
+			writeOrderStatusError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, dto.PaymentWebhookResponse{Received: true})
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	// Money for another amount than the order's does not pay for it. The
+	// payment fails like on a failed event, the event is still recorded so
+	// it is not delivered again.
+	eventType := event.Type
+	if (eventType == payment.EventAuthorized || eventType == payment.EventCaptured) && event.Amount != int64(p.Amount) {
+		log.Warn().Str("intentId", p.IntentID.String).Int64("amount", event.Amount).Int32("expected", p.Amount).Msg("payment webhook amount does not match")
+		eventType = payment.EventFailed
+	}
+
+	uncaptured := p.Status == db.PaymentStatusPending || p.Status == db.PaymentStatusAuthorized
+	switch eventType {
+	case payment.EventAuthorized:
+		if p.Status != db.PaymentStatusPending {
+			break
+		}
+		// A failed capture is answered with an error so the provider
+		// delivers the event again
+		if err := h.provider.Capture(c, p.IntentID.String); err != nil {
+			c.JSON(http.StatusBadGateway, dto.ErrorResponse{
+				Success: false,
+				Error:   "Failed to capture the payment",
+				Code:    http.StatusBadGateway,
+			})
+			return
+		}
+		err = capturePayment(c, qtx, p)
+	case payment.EventCaptured:
+		switch {
+		case uncaptured:
+			err = capturePayment(c, qtx, p)
+		case p.Status == db.PaymentStatusCancelled:
+			// The order was cancelled before the money arrived, it is owed
+			// back in full
+			err = qtx.UpdatePaymentStatus(c, db.UpdatePaymentStatusParams{ID: p.ID, Status: db.PaymentStatusCaptured})
+			if err == nil {
+				err = qtx.RequestOrderPaymentRefund(c, p.OrderID)
+			}
+		}
+	case payment.EventFailed:
+		if !uncaptured {
+			break
+		}
+		err = qtx.UpdatePaymentStatus(c, db.UpdatePaymentStatusParams{ID: p.ID, Status: db.PaymentStatusFailed})
+		if err == nil {
+			err = transitionOrder(c, qtx, p.OrderID, db.OrderStatusCancelled, orderActor{})
+		}
+	case payment.EventRefunded:
+		if p.Status == db.PaymentStatusRefundPending {
+			err = qtx.UpdatePaymentStatus(c, db.UpdatePaymentStatusParams{ID: p.ID, Status: db.PaymentStatusRefunded})
+		}
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if err := settlePaymentRefund(c, h.pool, h.Q, h.provider, p.OrderID); err != nil {
+		log.Error().Err(err).Str("orderId", p.OrderID.String()).Msg("failed to refund order payment")
+	}
+
+	c.JSON(http.StatusOK, dto.PaymentWebhookResponse{Received: true})
+}
+
+// paymentExpiryInterval is how often orders waiting for their payment are
+// checked, and paymentExpiryBatch how many are cancelled per check.
+const (
+	paymentExpiryInterval = time.Minute
+	paymentExpiryBatch    = 100
+)
+
+// ExpirePayments cancels orders still waiting for their payment after
+// timeout until ctx is done, which gives their reserved stock back. A payment
+// captured after that is refunded. Every replica may run it, an order is only
+// cancelled once.
+func (h *PaymentHandler) ExpirePayments(ctx context.Context, timeout time.Duration) {
+	ticker := time.NewTicker(paymentExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		orderIDs, err := h.Q.GetExpiredPaymentOrders(ctx, db.GetExpiredPaymentOrdersParams{
+			Cutoff:    pgtype.Timestamptz{Time: time.Now().Add(-timeout), Valid: true},
+			MaxOrders: paymentExpiryBatch,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("failed to list orders with expired payments")
+			continue
+		}
+		for _, orderID := range orderIDs {
+			if err := h.expirePayment(ctx, orderID); err != nil {
+				log.Error().Err(err).Str("orderId", orderID.String()).Msg("failed to cancel order with expired payment")
+			}
+		}
+	}
+}
+
+// expirePayment cancels an unpaid order. An order paid or cancelled since it
+// was listed is left alone.
+func (h *PaymentHandler) expirePayment(ctx context.Context, orderID pgtype.UUID) error {
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = transitionOrder(ctx, h.Q.WithTx(tx), orderID, db.OrderStatusCancelled, orderActor{})
+	var transitionErr *OrderTransitionError
+	if errors.As(err, &transitionErr) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// capturePayment marks the payment captured and places its order, which then
+// waits for a courier like an order paid from the wallet.
+func capturePayment(ctx context.Context, q *db.Queries, p db.Payment) error {
+	err := q.UpdatePaymentStatus(ctx, db.UpdatePaymentStatusParams{ID: p.ID, Status: db.PaymentStatusCaptured})
+	if err != nil {
+		return err
+	}
+	if err := transitionOrder(ctx, q, p.OrderID, db.OrderStatusPlaced, orderActor{}); err != nil {
+		return err
+	}
+	return q.AssignNearestCourier(ctx, p.OrderID)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// reorderRequest drops the items of a past order that can no longer be
+// ordered and reports them, along with repriced items, in the diff. Prices
+// are compared with the snapshot taken when the order was placed.
+func reorderRequest(req dto.EstimateRequest, catalog estimateCatalog, snapshot []db.GetOrderHistoryItemsRow) (dto.EstimateRequest, dto.ReorderDiff) {
+	type itemKey struct{ merchant, item pgtype.UUID }
+	ordered := make(map[itemKey]db.GetOrderHistoryItemsRow, len(snapshot))
+	for _, item := range snapshot {
+		ordered[itemKey{item.MerchantID, item.ItemID}] = item
+	}
+
+	requested := make(map[pgtype.UUID]int)
+	for _, order := range req.Orders {
+		for _, item := range order.Items {
+			requested[parseUUID(item.ItemId)] += item.Quantity
+		}
+	}
+
+	diff := dto.ReorderDiff{
+		Gone:        []dto.ReorderItem{},
+		Unavailable: []dto.ReorderItem{},
+		Repriced:    []dto.RepricedItem{},
+	}
+	next := dto.EstimateRequest{UserLocation: req.UserLocation}
+	startKept := false
+	for _, order := range req.Orders {
+		merchantID := parseUUID(order.MerchantId)
+		_, merchantExists := catalog.merchants[merchantID]
+
+		kept := dto.EstimateOrder{MerchantId: order.MerchantId, IsStartingPoint: order.IsStartingPoint}
+		for _, item := range order.Items {
+			itemID := parseUUID(item.ItemId)
+			previous := ordered[itemKey{merchantID, itemID}]
+			change := dto.ReorderItem{
+				MerchantID: order.MerchantId,
+				ItemID:     item.ItemId,
+				Name:       previous.Name,
+				Quantity:   item.Quantity,
+			}
+
+			current, ok := catalog.items[itemID]
+			if !merchantExists || !ok || current.MerchantID != merchantID {
+				diff.Gone = append(diff.Gone, change)
+				continue
+			}
+			if !itemAvailable(current.Available, current.Stock) ||
+				(current.Stock.Valid && int(current.Stock.Int32) < requested[itemID]) {
+				diff.Unavailable = append(diff.Unavailable, change)
+				continue
+			}
+			if _, problem := itemOptionsPrice(catalog, itemID, item.Options); problem != nil {
+				diff.Unavailable = append(diff.Unavailable, change)
+				continue
+			}
+
+			if previous.ItemID.Valid && previous.UnitPrice != current.Price {
+				diff.Repriced = append(diff.Repriced, dto.RepricedItem{
+					MerchantID:    order.MerchantId,
+					ItemID:        item.ItemId,
+					Name:          previous.Name,
+					PreviousPrice: int(previous.UnitPrice),
+					Price:         int(current.Price),
+				})
+			}
+			kept.Items = append(kept.Items, item)
+		}
+
+		if len(kept.Items) > 0 {
+			startKept = startKept || kept.IsStartingPoint
+			next.Orders = append(next.Orders, kept)
+		}
+	}
+
+	// The route must still start somewhere
+	if !startKept && len(next.Orders) > 0 {
+		next.Orders[0].IsStartingPoint = true
+	}
+	return next, diff
+}
+
+func (h *OrderHandler) Reorder(c *gin.Context) {
+	username, _ := c.Get("username")
+	user, err := h.Q.GetUserByUsername(c, username.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var orderID pgtype.UUID
+	if err := orderID.Scan(c.Param("orderId")); err != nil {
+		writeOrderStatusError(c, errOrderNotFound)
+		return
+	}
+
+	// Other users' orders read as not found
+	rawEstimate, err := h.Q.GetUserOrderEstimate(c, db.GetUserOrderEstimateParams{
+		ID:     orderID,
+		UserID: user.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeOrderStatusError(c, errOrderNotFound)
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	var estimateData dto.EstimateData
+	if err := json.Unmarshal(rawEstimate, &estimateData); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Success: false,
+			Error:   "Failed to read estimate data",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	// A past schedule makes no sense for the new order
+	req := estimateData.EstimateRequest
+	req.ScheduledAt = nil
+
+	snapshot, err := h.Q.GetOrderHistoryItems(c, []pgtype.UUID{orderID})
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	catalog, err := loadEstimateCatalog(c, h.Q, req)
+	if err != nil {
+		writeEstimateError(c, err)
+		return
+	}
+
+	req, diff := reorderRequest(req, catalog, snapshot)
+	if len(req.Orders) == 0 {
+		c.JSON(http.StatusConflict, dto.NothingToReorderResponse{
+			ErrorResponse: dto.ErrorResponse{
+				Success: false,
+				Error:   "None of the items of this order can be ordered again",
+				Code:    http.StatusConflict,
+				Reason:  dto.ReasonNothingToReorder,
+			},
+			Data: diff,
+		})
+		return
+	}
+
+	quote, err := quoteEstimate(c, h.Q, h.cfg, req, false)
+	if err != nil {
+		writeEstimateError(c, err)
+		return
+	}
+
+	estimateID, err := storeEstimate(c, h.Q, h.cfg, user.ID, req, quote)
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ReorderResponse{
+		EstimateResponse: quote.response(estimateID),
+		Diff:             diff,
+	})
+}
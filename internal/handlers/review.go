@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// merchantRating reads the running totals kept on merchants.
+func merchantRating(count int32, average float64) *dto.MerchantRating {
+	return &dto.MerchantRating{Average: average, Count: int(count)}
+}
+
+// parseRatingFilters reads minRating and the rating sort. ok is false when
+// minRating is not a number between 0 and 5.
+func parseRatingFilters(c *gin.Context) (minRating pgtype.Float8, sort string, ok bool) {
+	if value := c.Query("minRating"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 5 {
+			return minRating, "", false
+		}
+		minRating = pgtype.Float8{Float64: parsed, Valid: true}
+	}
+
+	// Unlike createdAt there is no default, listings keep their own order
+	sort = c.Query("rating")
+	if sort != "asc" && sort != "desc" {
+		sort = ""
+	}
+	return minRating, sort, true
+}
+
+func (h *OrderHandler) CreateReview(c *gin.Context) {
+	var req dto.CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body: merchantId and a rating from 1 to 5 are required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if req.ImageURL != "" && !isValidImageURL(req.ImageURL) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid image URL format",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	username, _ := c.Get("username")
+	user, err := h.Q.GetUserByUsername(c, username.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var orderID pgtype.UUID
+	if err := orderID.Scan(c.Param("orderId")); err != nil {
+		writeOrderStatusError(c, errOrderNotFound)
+		return
+	}
+	merchantID := parseUUID(req.MerchantID)
+
+	tx, err := h.pool.Begin(c)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	defer tx.Rollback(c)
+	qtx := h.Q.WithTx(tx)
+
+	// Other users' orders read as not found
+	status, err := qtx.GetUserOrderStatus(c, db.GetUserOrderStatusParams{
+		ID:     orderID,
+		UserID: user.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeOrderStatusError(c, errOrderNotFound)
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	if status != db.OrderStatusDelivered {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Success: false,
+			Error:   "Only delivered orders can be reviewed",
+			Code:    http.StatusConflict,
+			Reason:  dto.ReasonOrderNotDelivered,
+		})
+		return
+	}
+
+	_, err = qtx.GetOrderMerchantStatus(c, db.GetOrderMerchantStatusParams{
+		OrderID:    orderID,
+		MerchantID: merchantID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Merchant is not part of this order",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	err = qtx.CreateMerchantReview(c, db.CreateMerchantReviewParams{
+		OrderID:    orderID,
+		MerchantID: merchantID,
+		UserID:     user.ID,
+		Rating:     int16(req.Rating),
+		Comment:    pgtype.Text{String: comment, Valid: comment != ""},
+		ImageUrl:   pgtype.Text{String: req.ImageURL, Valid: req.ImageURL != ""},
+	})
+	if shared.IsUniqueViolation(err, "merchant_reviews_pkey") {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Success: false,
+			Error:   "This merchant was already reviewed for this order",
+			Code:    http.StatusConflict,
+			Reason:  dto.ReasonAlreadyReviewed,
+		})
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	err = qtx.AddMerchantRating(c, db.AddMerchantRatingParams{
+		Rating: int32(req.Rating),
+		ID:     merchantID,
+	})
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.CreateReviewResponse{
+		OrderID:    orderID.String(),
+		MerchantID: merchantID.String(),
+		Rating:     req.Rating,
+	})
+}
+
+func (h *MerchantHandler) GetMerchantReviews(c *gin.Context) {
+	// Parse limit and offset with defaults
+	limit := int32(5)
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if val, err := strconv.ParseInt(limitStr, 10, 32); err == nil && val > 0 {
+			limit = int32(val)
+		}
+	}
+
+	offset := int32(0)
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if val, err := strconv.ParseInt(offsetStr, 10, 32); err == nil && val >= 0 {
+			offset = int32(val)
+		}
+	}
+
+	var merchantID pgtype.UUID
+	if err := merchantID.Scan(c.Param("merchantId")); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Merchant not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	queries := db.New(h.pool)
+	ctx := context.Background()
+
+	exists, err := queries.GetActiveMerchantByID(ctx, merchantID)
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Merchant not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	total, err := queries.CountMerchantReviews(ctx, merchantID)
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	reviews, err := queries.GetMerchantReviews(ctx, db.GetMerchantReviewsParams{
+		MerchantID: merchantID,
+		OffsetVal:  offset,
+		LimitVal:   limit,
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	data := make([]dto.ReviewData, 0, len(reviews))
+	for _, r := range reviews {
+		data = append(data, dto.ReviewData{
+			OrderID:   r.OrderID.String(),
+			Username:  r.Username,
+			Rating:    int(r.Rating),
+			Comment:   r.Comment.String,
+			ImageURL:  r.ImageUrl.String,
+			CreatedAt: r.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.GetMerchantReviewsResponse{
+		Data: data,
+		Meta: dto.MerchantMeta{
+			Limit:  int(limit),
+			Offset: int(offset),
+			Total:  int(total),
+		},
+	})
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var errItemsUnavailable = errors.New("one or more items are unavailable")
+
+// itemAvailable reports whether an item can be ordered: it is switched on and,
+// when its stock is tracked, not sold out.
+func itemAvailable(available bool, stock pgtype.Int4) bool {
+	return available && (!stock.Valid || stock.Int32 > 0)
+}
+
+// reserveOrderStock takes the stock of every item in req for the order. It
+// returns errItemsUnavailable when an item was switched off, sold out or
+// deleted since the estimate, in which case the caller must roll back.
+func reserveOrderStock(ctx context.Context, q *db.Queries, orderID pgtype.UUID, req dto.EstimateRequest) error {
+	quantities := make(map[pgtype.UUID]int32)
+	for _, order := range req.Orders {
+		for _, item := range order.Items {
+			quantities[parseUUID(item.ItemId)] += int32(item.Quantity)
+		}
+	}
+
+	ids := make([]pgtype.UUID, 0, len(quantities))
+	counts := make([]int32, 0, len(quantities))
+	for id, quantity := range quantities {
+		ids = append(ids, id)
+		counts = append(counts, quantity)
+	}
+
+	reserved, err := q.ReserveItemStock(ctx, db.ReserveItemStockParams{
+		ItemIds:    ids,
+		Quantities: counts,
+	})
+	if err != nil {
+		return err
+	}
+	if len(reserved) != len(ids) {
+		return errItemsUnavailable
+	}
+
+	// Only tracked stock has to be given back later
+	trackedIDs := make([]pgtype.UUID, 0, len(reserved))
+	trackedCounts := make([]int32, 0, len(reserved))
+	for _, r := range reserved {
+		if r.Tracked {
+			trackedIDs = append(trackedIDs, r.ID)
+			trackedCounts = append(trackedCounts, quantities[r.ID])
+		}
+	}
+	if len(trackedIDs) == 0 {
+		return nil
+	}
+
+	return q.CreateOrderReservations(ctx, db.CreateOrderReservationsParams{
+		OrderID:    orderID,
+		ItemIds:    trackedIDs,
+		Quantities: trackedCounts,
+	})
+}
+
+func (h *MerchantHandler) UpdateItemAvailability(c *gin.Context) {
+	var payload dto.MerchantItemAvailabilityRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: available is required and stock must not be negative",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var merchantUUID, itemUUID pgtype.UUID
+	if err := merchantUUID.Scan(c.Param("merchantId")); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid merchant ID format",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if err := itemUUID.Scan(c.Param("itemId")); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid item ID format",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var stock pgtype.Int4
+	if payload.Stock != nil {
+		stock = pgtype.Int4{Int32: int32(*payload.Stock), Valid: true}
+	}
+
+	queries := db.New(h.pool)
+	ctx := context.Background()
+
+	updated, err := queries.UpdateMerchantItemAvailability(ctx, db.UpdateMerchantItemAvailabilityParams{
+		Available:  *payload.Available,
+		Stock:      stock,
+		ID:         itemUUID,
+		MerchantID: merchantUUID,
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+	if updated == 0 {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "Item not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MerchantItemAvailabilityResponse{
+		ItemId:    itemUUID.String(),
+		Available: itemAvailable(*payload.Available, stock),
+		Stock:     payload.Stock,
+	})
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WalletHandler struct {
+	Q    *db.Queries
+	pool *pgxpool.Pool
+}
+
+func NewWalletHandler(pool *pgxpool.Pool) *WalletHandler {
+	q := db.New(pool)
+	return &WalletHandler{Q: q, pool: pool}
+}
+
+// InsufficientBalanceError is returned when a wallet cannot pay an amount.
+type InsufficientBalanceError struct {
+	Balance int64
+	Amount  int64
+}
+
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("balance %d does not cover %d", e.Balance, e.Amount)
+}
+
+// debitOrder pays for an order from the user's wallet. q must run inside the
+// transaction creating the order, the wallet stays locked until it ends.
+func debitOrder(ctx context.Context, q *db.Queries, userID, orderID pgtype.UUID, amount int64) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	walletID, err := q.LockUserWallet(ctx, userID)
+	if err != nil {
+		return err
+	}
+	balance, err := q.GetAccountBalance(ctx, walletID)
+	if err != nil {
+		return err
+	}
+	if balance < amount {
+		return &InsufficientBalanceError{Balance: balance, Amount: amount}
+	}
+
+	ordersID, err := q.GetLedgerAccountID(ctx, db.LedgerAccountTypeOrders)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateLedgerTransfer(ctx, db.CreateLedgerTransferParams{
+		Kind:        db.LedgerTransactionKindOrderPayment,
+		OrderID:     orderID,
+		FromAccount: walletID,
+		Amount:      amount,
+		ToAccount:   ordersID,
+	})
+	return err
+}
+
+// refundOrder gives the payment of a cancelled or rejected order back to the
+// wallet it came from. Orders placed before wallets existed were not paid
+// through the ledger and have nothing to refund.
+func refundOrder(ctx context.Context, q *db.Queries, orderID pgtype.UUID) error {
+	refund, err := q.GetOrderRefund(ctx, orderID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if refund.Amount <= 0 {
+		return nil
+	}
+
+	ordersID, err := q.GetLedgerAccountID(ctx, db.LedgerAccountTypeOrders)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateLedgerTransfer(ctx, db.CreateLedgerTransferParams{
+		Kind:        db.LedgerTransactionKindOrderRefund,
+		OrderID:     orderID,
+		FromAccount: ordersID,
+		Amount:      refund.Amount,
+		ToAccount:   refund.WalletID,
+	})
+	return err
+}
+
+// writeInsufficientBalance writes the 402 returned when the wallet cannot
+// pay for an order.
+func writeInsufficientBalance(c *gin.Context, err *InsufficientBalanceError) {
+	c.JSON(http.StatusPaymentRequired, dto.InsufficientBalanceResponse{
+		ErrorResponse: dto.ErrorResponse{
+			Success: false,
+			Error:   "Wallet balance is not enough to pay for this order",
+			Code:    http.StatusPaymentRequired,
+			Reason:  dto.ReasonInsufficientBalance,
+		},
+		Data: dto.InsufficientBalanceData{
+			Balance:  err.Balance,
+			Required: err.Amount,
+		},
+	})
+}
+
+// TopUpWallet credits a user's wallet. Until payments exist admins credit
+// wallets by hand.
+func (h *WalletHandler) TopUpWallet(c *gin.Context) {
+	var req dto.WalletTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body: username and a positive amount are required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	username, _ := c.Get("username")
+	admin, err := h.Q.GetAdminByUsername(c, username.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "Admin not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	user, err := h.Q.GetUserByUsername(c, req.Username)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	tx, err := h.pool.Begin(c)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	defer tx.Rollback(c)
+	qtx := h.Q.WithTx(tx)
+
+	walletID, err := qtx.LockUserWallet(c, user.ID)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	topUpID, err := qtx.GetLedgerAccountID(c, db.LedgerAccountTypeTopUp)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	transactionID, err := qtx.CreateLedgerTransfer(c, db.CreateLedgerTransferParams{
+		Kind:        db.LedgerTransactionKindTopUp,
+		CreatedBy:   admin.ID,
+		FromAccount: topUpID,
+		Amount:      req.Amount,
+		ToAccount:   walletID,
+	})
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	balance, err := qtx.GetAccountBalance(c, walletID)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.WalletTopUpResponse{
+		TransactionID: transactionID.String(),
+		Username:      user.Username,
+		Amount:        req.Amount,
+		Balance:       balance,
+	})
+}
+
+// GetWallet returns the user's balance and wallet entries, latest first.
+func (h *WalletHandler) GetWallet(c *gin.Context) {
+	// Parse limit and offset with defaults
+	limit := int32(5)
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if val, err := strconv.ParseInt(limitStr, 10, 32); err == nil && val > 0 {
+			limit = int32(val)
+		}
+	}
+
+	offset := int32(0)
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if val, err := strconv.ParseInt(offsetStr, 10, 32); err == nil && val >= 0 {
+			offset = int32(val)
+		}
+	}
+
+	username, _ := c.Get("username")
+	user, err := h.Q.GetUserByUsername(c, username.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	balance, err := h.Q.GetUserBalance(c, user.ID)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	total, err := h.Q.CountUserWalletEntries(c, user.ID)
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+	entries, err := h.Q.GetUserWalletEntries(c, db.GetUserWalletEntriesParams{
+		UserID:    user.ID,
+		OffsetVal: offset,
+		LimitVal:  limit,
+	})
+	if err != nil {
+		writeOrderStatusError(c, err)
+		return
+	}
+
+	data := make([]dto.WalletEntry, 0, len(entries))
+	for _, e := range entries {
+		entry := dto.WalletEntry{
+			TransactionID: e.ID.String(),
+			Kind:          string(e.Kind),
+			Amount:        e.Amount,
+			CreatedAt:     e.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+		}
+		if e.OrderID.Valid {
+			orderID := e.OrderID.String()
+			entry.OrderID = &orderID
+		}
+		data = append(data, entry)
+	}
+
+	c.JSON(http.StatusOK, dto.GetWalletResponse{
+		Balance: balance,
+		Data:    data,
+		Meta: dto.MerchantMeta{
+			Limit:  int(limit),
+			Offset: int(offset),
+			Total:  int(total),
+		},
+	})
+}
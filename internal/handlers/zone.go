@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/db"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ZoneHandler manages delivery zones. A merchant located inside a zone only
+// delivers within that zone and the zone's radius, overriding its own radius.
+type ZoneHandler struct {
+	pool *pgxpool.Pool
+}
+
+func NewZoneHandler(pool *pgxpool.Pool) *ZoneHandler {
+	return &ZoneHandler{pool: pool}
+}
+
+func (h *ZoneHandler) CreateDeliveryZone(c *gin.Context) {
+	var payload dto.DeliveryZoneCreateRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: please make sure you have provided a name, delivery radius and at least 3 area points",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	for _, point := range payload.Area {
+		if point.Lat < -90 || point.Lat > 90 || point.Long < -180 || point.Long > 180 {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Success: false,
+				Error:   "Invalid area point. Latitude must be between -90 and 90 and longitude between -180 and 180",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	queries := db.New(h.pool)
+	ctx := context.Background()
+
+	id, err := queries.CreateDeliveryZone(ctx, db.CreateDeliveryZoneParams{
+		Name:            payload.Name,
+		AreaWkt:         polygonWKT(payload.Area),
+		DeliveryRadiusM: int32(payload.DeliveryRadiusM),
+	})
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.DeliveryZoneCreateResponse{
+		ZoneId: id.String(),
+	})
+}
+
+func (h *ZoneHandler) GetDeliveryZones(c *gin.Context) {
+	queries := db.New(h.pool)
+	ctx := context.Background()
+
+	zones, err := queries.GetDeliveryZones(ctx)
+	if err != nil {
+		statusCode, errorMessage := shared.ParseDBResult(err)
+		c.JSON(statusCode, dto.ErrorResponse{
+			Success: false,
+			Error:   errorMessage,
+			Code:    statusCode,
+		})
+		return
+	}
+
+	data := make([]dto.DeliveryZoneData, 0, len(zones))
+	for _, z := range zones {
+		data = append(data, dto.DeliveryZoneData{
+			ZoneID:          z.ID.String(),
+			Name:            z.Name,
+			DeliveryRadiusM: int(z.DeliveryRadiusM),
+			Area:            json.RawMessage(z.Area),
+			CreatedAt:       z.CreatedAt.Time.Format(shared.ISO8601WithNanoseconds),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.GetDeliveryZonesResponse{Data: data})
+}
+
+// polygonWKT renders the points as a WKT polygon, closing the ring when the
+// last point does not repeat the first.
+func polygonWKT(points []dto.Location) string {
+	ring := make([]string, 0, len(points)+1)
+	for _, p := range points {
+		ring = append(ring, fmt.Sprintf("%f %f", p.Long, p.Lat))
+	}
+	if first, last := points[0], points[len(points)-1]; first != last {
+		ring = append(ring, ring[0])
+	}
+	return "POLYGON((" + strings.Join(ring, ", ") + "))"
+}
@@ -2,15 +2,17 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/dto"
 	"github.com/gin-gonic/gin"
 )
 
-func IsAuthorized(role string) gin.HandlerFunc {
+// IsAuthorized lets the request through when the token has one of roles
+func IsAuthorized(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("role")
-		if !exists || userRole != role {
+		if !exists || !slices.Contains(roles, userRole.(string)) {
 			c.JSON(http.StatusForbidden, dto.ErrorResponse{
 				Success: false,
 				Error:   "Forbidden: insufficient role",
@@ -0,0 +1,58 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Webhook event types, as every provider's own events are mapped to them
+const (
+	// EventAuthorized means the customer approved the payment, it still has
+	// to be captured
+	EventAuthorized = "payment.authorized"
+	EventCaptured   = "payment.captured"
+	EventFailed     = "payment.failed"
+	EventRefunded   = "payment.refunded"
+)
+
+var (
+	// ErrInvalidSignature is returned by VerifyWebhook for requests the
+	// provider did not sign
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrUnknownIntent is returned for intents the provider does not know
+	ErrUnknownIntent = errors.New("unknown payment intent")
+)
+
+// Intent is a payment the customer is asked to complete with the provider.
+// ClientSecret is handed to the client to confirm the payment.
+type Intent struct {
+	ID           string
+	ClientSecret string
+	Amount       int64
+}
+
+// WebhookEvent is a verified notification from the provider. ID is unique
+// per event and used to ignore redeliveries.
+type WebhookEvent struct {
+	ID       string
+	Type     string
+	IntentID string
+	Amount   int64
+}
+
+// PaymentProvider is a card or e-wallet gateway. Amounts are in the smallest
+// currency unit. Capture and Refund are retried on redelivered webhooks and
+// must be idempotent for the same intent.
+type PaymentProvider interface {
+	// Name identifies the provider on stored payments
+	Name() string
+	// CreateIntent starts a payment of amount. reference is our id for it,
+	// the order id.
+	CreateIntent(ctx context.Context, amount int64, reference string) (Intent, error)
+	Capture(ctx context.Context, intentID string) error
+	Refund(ctx context.Context, intentID string, amount int64) error
+	// VerifyWebhook checks the signature of a webhook request and parses its
+	// event.
+	VerifyWebhook(header http.Header, body []byte) (WebhookEvent, error)
+}
@@ -6,7 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(router *gin.Engine, adminHandler *handlers.AdminHandler, userHandler *handlers.UserHandler, merchantHandler *handlers.MerchantHandler, imageHandler *handlers.ImageHandler, estimateHandler *handlers.EstimateHandler, orderHandler *handlers.OrderHandler) {
+func SetupRoutes(router *gin.Engine, adminHandler *handlers.AdminHandler, userHandler *handlers.UserHandler, merchantHandler *handlers.MerchantHandler, imageHandler *handlers.ImageHandler, estimateHandler *handlers.EstimateHandler, orderHandler *handlers.OrderHandler, zoneHandler *handlers.ZoneHandler, eventHandler *handlers.EventHandler, courierHandler *handlers.CourierHandler, walletHandler *handlers.WalletHandler, paymentHandler *handlers.PaymentHandler) {
 	admin := router.Group("/admin")
 	{
 		admin.POST("/register", adminHandler.RegisterAdmin)
@@ -17,8 +17,44 @@ func SetupRoutes(router *gin.Engine, adminHandler *handlers.AdminHandler, userHa
 		{
 			merchant.GET("", merchantHandler.GetMerchants)
 			merchant.POST("/", merchantHandler.CreateMerchant)
+			merchant.PATCH("/:merchantId", merchantHandler.UpdateMerchant)
+			merchant.DELETE("/:merchantId", merchantHandler.DeleteMerchant)
+			merchant.POST("/:merchantId/restore", merchantHandler.RestoreMerchant)
 			merchant.GET("/:merchantId/items", merchantHandler.GetMerchantItems)
 			merchant.POST("/:merchantId/items", merchantHandler.CreateMerchantItem)
+			merchant.PATCH("/:merchantId/items/:itemId", merchantHandler.UpdateMerchantItem)
+			merchant.DELETE("/:merchantId/items/:itemId", merchantHandler.DeleteMerchantItem)
+			merchant.POST("/:merchantId/items/:itemId/restore", merchantHandler.RestoreMerchantItem)
+			merchant.PUT("/:merchantId/items/:itemId/availability", merchantHandler.UpdateItemAvailability)
+			merchant.PUT("/:merchantId/items/:itemId/options", merchantHandler.ReplaceItemOptionGroups)
+			merchant.GET("/:merchantId/opening-hours", merchantHandler.GetOpeningHours)
+			merchant.PUT("/:merchantId/opening-hours", merchantHandler.UpdateOpeningHours)
+			merchant.GET("/:merchantId/holidays", merchantHandler.GetHolidays)
+			merchant.PUT("/:merchantId/holidays/:date", merchantHandler.UpsertHoliday)
+			merchant.DELETE("/:merchantId/holidays/:date", merchantHandler.DeleteHoliday)
+			merchant.GET("/:merchantId/orders", orderHandler.GetMerchantOrders)
+			merchant.POST("/:merchantId/orders/:orderId/accept", orderHandler.AcceptMerchantOrder)
+			merchant.POST("/:merchantId/orders/:orderId/reject", orderHandler.RejectMerchantOrder)
+			merchant.POST("/:merchantId/orders/:orderId/ready", orderHandler.MarkMerchantOrderReady)
+		}
+
+		orders := admin.Group("/orders")
+		orders.Use(middleware.AuthMiddleware(), middleware.IsAuthorized("admin"))
+		{
+			orders.PATCH("/:orderId/status", orderHandler.UpdateOrderStatus)
+		}
+
+		zones := admin.Group("/zones")
+		zones.Use(middleware.AuthMiddleware(), middleware.IsAuthorized("admin"))
+		{
+			zones.GET("", zoneHandler.GetDeliveryZones)
+			zones.POST("", zoneHandler.CreateDeliveryZone)
+		}
+
+		wallets := admin.Group("/wallets")
+		wallets.Use(middleware.AuthMiddleware(), middleware.IsAuthorized("admin"))
+		{
+			wallets.POST("/top-ups", walletHandler.TopUpWallet)
 		}
 	}
 
@@ -26,13 +62,48 @@ func SetupRoutes(router *gin.Engine, adminHandler *handlers.AdminHandler, userHa
 	{
 		users.POST("/register", userHandler.RegisterUser)
 		users.POST("/login", userHandler.LoginUser)
+		users.GET("/wallet", middleware.AuthMiddleware(), middleware.IsAuthorized("user"), walletHandler.GetWallet)
 		users.POST("/estimate", middleware.AuthMiddleware(), middleware.IsAuthorized("user"), estimateHandler.Estimate)
 		users.POST("/orders", middleware.AuthMiddleware(), middleware.IsAuthorized("user"), orderHandler.CreateOrder)
 		users.GET("/orders", middleware.AuthMiddleware(), middleware.IsAuthorized("user"), orderHandler.GetOrders)
+		users.POST("/orders/:orderId/cancel", middleware.AuthMiddleware(), middleware.IsAuthorized("user"), orderHandler.CancelOrder)
+		users.POST("/orders/:orderId/reorder", middleware.AuthMiddleware(), middleware.IsAuthorized("user"), orderHandler.Reorder)
+		users.POST("/orders/:orderId/reviews", middleware.AuthMiddleware(), middleware.IsAuthorized("user"), orderHandler.CreateReview)
+		users.GET("/orders/:orderId/courier", middleware.AuthMiddleware(), middleware.IsAuthorized("user"), orderHandler.GetCourierPosition)
+		users.POST("/orders/:orderId/events/ticket", middleware.AuthMiddleware(), middleware.IsAuthorized("user"), eventHandler.CreateOrderEventsTicket)
+		users.GET("/orders/:orderId/events", middleware.StreamAuthMiddleware(), middleware.IsAuthorized("user"), eventHandler.StreamOrderEvents)
+		users.GET("/orders/:orderId/events/ws", middleware.StreamAuthMiddleware(), middleware.IsAuthorized("user"), eventHandler.OrderEventsSocket)
+	}
+
+	courier := router.Group("/courier")
+	{
+		courier.POST("/register", courierHandler.RegisterCourier)
+		courier.POST("/login", courierHandler.LoginCourier)
+
+		authorized := courier.Group("")
+		authorized.Use(middleware.AuthMiddleware(), middleware.IsAuthorized("courier"))
+		{
+			authorized.PUT("/availability", courierHandler.UpdateAvailability)
+			authorized.POST("/location", courierHandler.RecordLocation)
+			authorized.GET("/orders", courierHandler.GetCourierOrders)
+			authorized.POST("/orders/:orderId/accept", courierHandler.AcceptCourierOrder)
+			authorized.POST("/orders/:orderId/merchants/:merchantId/pickup", courierHandler.PickUpOrderMerchant)
+			authorized.POST("/orders/:orderId/deliver", courierHandler.DeliverCourierOrder)
+		}
+	}
+
+	// Called by the payment provider, requests are verified by their signature
+	payments := router.Group("/payments")
+	{
+		payments.POST("/webhook", paymentHandler.Webhook)
+		// Completes fake provider payments during local work, it places orders
+		// without charging anything
+		payments.POST("/fake/:intentId/:event", middleware.AuthMiddleware(), middleware.IsAuthorized("admin"), paymentHandler.SimulateFakePayment)
 	}
 
+	// Users upload review photos here too
 	image := router.Group("/image")
-	image.Use(middleware.AuthMiddleware(), middleware.IsAuthorized("admin"))
+	image.Use(middleware.AuthMiddleware(), middleware.IsAuthorized("admin", "user"))
 	{
 		image.POST("", imageHandler.UploadImage)
 	}
@@ -43,5 +114,6 @@ func SetupRoutes(router *gin.Engine, adminHandler *handlers.AdminHandler, userHa
 	{
 		// Path pattern: /merchants/nearby/:coords where :coords is "lat,long"
 		merchants.GET("/nearby/:coords", merchantHandler.GetNearbyMerchants)
+		merchants.GET("/:merchantId/reviews", merchantHandler.GetMerchantReviews)
 	}
 }
@@ -0,0 +1,62 @@
+package shared
+
+import "math"
+
+// Fee line types returned by CalculateFees
+const (
+	FeeBaseDelivery = "baseDelivery"
+	FeeDistance     = "distance"
+	FeeSmallOrder   = "smallOrderSurcharge"
+	FeeService      = "service"
+)
+
+// FeeSchedule is the fee configuration of one merchant category.
+type FeeSchedule struct {
+	BaseDeliveryFee     int
+	PerKmFee            int
+	SmallOrderThreshold int
+	SmallOrderSurcharge int
+	ServiceFeePercent   int
+}
+
+// MerchantCharge is the item subtotal of one merchant and its category schedule.
+type MerchantCharge struct {
+	Subtotal int
+	Schedule FeeSchedule
+}
+
+type FeeLine struct {
+	Type   string
+	Amount int
+}
+
+// CalculateFees prices one delivery trip over distanceKm. The trip is a single
+// courier run, so it uses the highest base and per km rate among the merchants.
+// Small order surcharges and service fees apply per merchant. Optional lines
+// are left out when they come to zero.
+func CalculateFees(distanceKm float64, merchants []MerchantCharge) []FeeLine {
+	baseFee, perKmFee := 0, 0
+	surcharge, service := 0, 0
+	for _, m := range merchants {
+		baseFee = max(baseFee, m.Schedule.BaseDeliveryFee)
+		perKmFee = max(perKmFee, m.Schedule.PerKmFee)
+
+		if m.Subtotal < m.Schedule.SmallOrderThreshold {
+			surcharge += m.Schedule.SmallOrderSurcharge
+		}
+		service += int(math.Round(float64(m.Subtotal*m.Schedule.ServiceFeePercent) / 100))
+	}
+
+	lines := []FeeLine{
+		{Type: FeeBaseDelivery, Amount: baseFee},
+		{Type: FeeDistance, Amount: int(math.Round(distanceKm * float64(perKmFee)))},
+	}
+	if surcharge > 0 {
+		lines = append(lines, FeeLine{Type: FeeSmallOrder, Amount: surcharge})
+	}
+	if service > 0 {
+		lines = append(lines, FeeLine{Type: FeeService, Amount: service})
+	}
+
+	return lines
+}
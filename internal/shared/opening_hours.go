@@ -0,0 +1,111 @@
+package shared
+
+import (
+	"time"
+
+	// Embed the zone database so merchant time zones resolve on minimal images
+	_ "time/tzdata"
+)
+
+// DefaultTimeZone is the time zone of merchants that never configured one.
+const DefaultTimeZone = "Asia/Jakarta"
+
+// OpeningSearchDays bounds how far ahead NextOpen looks for an opening.
+const OpeningSearchDays = 31
+
+// OpeningWindow is a weekly opening period in the merchant's local time.
+// Opens and Closes are minutes since midnight. A window that closes at or
+// before it opens runs past midnight into the next day.
+type OpeningWindow struct {
+	Weekday time.Weekday
+	Opens   int
+	Closes  int
+}
+
+// OpeningHours is a merchant's weekly schedule. A merchant without windows is
+// always open, except on holidays.
+type OpeningHours struct {
+	Location *time.Location
+	Windows  []OpeningWindow
+	// Holidays holds local dates formatted as DateLayout. A window that
+	// opens on a holiday is skipped.
+	Holidays map[string]bool
+}
+
+// DateLayout is the format of holiday dates.
+const DateLayout = "2006-01-02"
+
+// NewOpeningHours builds a schedule, falling back to DefaultTimeZone when the
+// zone name is unknown.
+func NewOpeningHours(timeZone string, windows []OpeningWindow, holidays []string) OpeningHours {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		loc, _ = time.LoadLocation(DefaultTimeZone)
+	}
+
+	hours := OpeningHours{Location: loc, Windows: windows, Holidays: make(map[string]bool, len(holidays))}
+	for _, day := range holidays {
+		hours.Holidays[day] = true
+	}
+	return hours
+}
+
+// IsOpen reports whether the merchant is open at t.
+func (h OpeningHours) IsOpen(t time.Time) bool {
+	next, ok := h.NextOpen(t)
+	return ok && next.Equal(t)
+}
+
+// NextOpen returns the earliest instant from t on at which the merchant is
+// open, which is t itself when it is open already. ok is false when there is
+// no opening within OpeningSearchDays.
+func (h OpeningHours) NextOpen(t time.Time) (next time.Time, ok bool) {
+	local := t.In(h.Location)
+
+	// Start a day early so that a window running past midnight is seen
+	for offset := -1; offset <= OpeningSearchDays; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, h.Location)
+		if h.Holidays[day.Format(DateLayout)] {
+			continue
+		}
+
+		if len(h.Windows) == 0 {
+			end := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, h.Location)
+			if !end.After(t) {
+				continue
+			}
+			if day.After(t) {
+				return day, true
+			}
+			return t, true
+		}
+
+		found := false
+		for _, w := range h.Windows {
+			if w.Weekday != day.Weekday() {
+				continue
+			}
+			start := time.Date(day.Year(), day.Month(), day.Day(), 0, w.Opens, 0, 0, h.Location)
+			end := time.Date(day.Year(), day.Month(), day.Day(), 0, w.Closes, 0, 0, h.Location)
+			if w.Closes <= w.Opens {
+				end = end.AddDate(0, 0, 1)
+			}
+			if !end.After(t) {
+				continue
+			}
+			if !start.After(t) {
+				return t, true
+			}
+			if !found || start.Before(next) {
+				next, found = start, true
+			}
+		}
+		// Windows opening on later days start later, so the first day with
+		// a candidate holds the earliest one
+		if found {
+			return next, true
+		}
+	}
+
+	return time.Time{}, false
+}
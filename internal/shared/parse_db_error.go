@@ -35,7 +35,7 @@ func ParseDBResult(err error) (int, string) {
 		case "23502": // not null error
 			return http.StatusBadRequest, "Required field is missing"
 
-		case "23414": // check error
+		case "23514": // check error
 			return http.StatusBadRequest, "Invalid data provided"
 
 		case "42P01": // Undefined table
@@ -56,3 +56,9 @@ func ParseDBResult(err error) (int, string) {
 
 	return http.StatusInternalServerError, "Database operation failed"
 }
+
+// IsUniqueViolation reports whether err is a duplicate key error on the given constraint
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
@@ -0,0 +1,170 @@
+package shared
+
+import "math"
+
+// ExactRouteMaxStops is the largest number of intermediate stops that is
+// solved exactly. Above it the planner falls back to a heuristic.
+const ExactRouteMaxStops = 10
+
+// RoutePoint is a coordinate on a delivery route.
+type RoutePoint struct {
+	Lat  float64
+	Long float64
+}
+
+// Route is a planned path from a fixed start, through every stop, to a fixed end.
+type Route struct {
+	// Order holds indexes into the stops slice in visiting order.
+	Order []int
+	// Legs holds the distance in km of every hop: start to the first stop,
+	// between stops, and the last stop to the end. It has len(Order)+1 entries.
+	Legs []float64
+	// Total is the sum of Legs in km.
+	Total float64
+}
+
+func distance(a, b RoutePoint) float64 {
+	return Haversine(a.Lat, a.Long, b.Lat, b.Long)
+}
+
+// PlanRoute finds the shortest path that leaves start, visits every stop once
+// and finishes at end. Up to ExactRouteMaxStops stops are solved exactly with
+// Held-Karp; larger inputs use nearest neighbour improved by 2-opt.
+func PlanRoute(start RoutePoint, stops []RoutePoint, end RoutePoint) Route {
+	var order []int
+	if len(stops) <= ExactRouteMaxStops {
+		order = exactOrder(start, stops, end)
+	} else {
+		order = heuristicOrder(start, stops, end)
+	}
+
+	route := Route{Order: order, Legs: make([]float64, 0, len(order)+1)}
+	prev := start
+	for _, idx := range order {
+		leg := distance(prev, stops[idx])
+		route.Legs = append(route.Legs, leg)
+		route.Total += leg
+		prev = stops[idx]
+	}
+	last := distance(prev, end)
+	route.Legs = append(route.Legs, last)
+	route.Total += last
+
+	return route
+}
+
+// exactOrder solves the fixed-endpoint path with Held-Karp dynamic programming.
+func exactOrder(start RoutePoint, stops []RoutePoint, end RoutePoint) []int {
+	n := len(stops)
+	if n == 0 {
+		return []int{}
+	}
+
+	full := 1 << n
+	cost := make([][]float64, full)
+	parent := make([][]int, full)
+	for mask := range cost {
+		cost[mask] = make([]float64, n)
+		parent[mask] = make([]int, n)
+		for j := range cost[mask] {
+			cost[mask][j] = math.Inf(1)
+			parent[mask][j] = -1
+		}
+	}
+	for j := 0; j < n; j++ {
+		cost[1<<j][j] = distance(start, stops[j])
+	}
+
+	for mask := 1; mask < full; mask++ {
+		for j := 0; j < n; j++ {
+			if mask&(1<<j) == 0 || math.IsInf(cost[mask][j], 1) {
+				continue
+			}
+			for k := 0; k < n; k++ {
+				if mask&(1<<k) != 0 {
+					continue
+				}
+				next := mask | 1<<k
+				candidate := cost[mask][j] + distance(stops[j], stops[k])
+				if candidate < cost[next][k] {
+					cost[next][k] = candidate
+					parent[next][k] = j
+				}
+			}
+		}
+	}
+
+	best, last := math.Inf(1), 0
+	for j := 0; j < n; j++ {
+		candidate := cost[full-1][j] + distance(stops[j], end)
+		if candidate < best {
+			best, last = candidate, j
+		}
+	}
+
+	order := make([]int, n)
+	mask := full - 1
+	for i := n - 1; i >= 0; i-- {
+		order[i] = last
+		prev := parent[mask][last]
+		mask &^= 1 << last
+		last = prev
+	}
+
+	return order
+}
+
+// heuristicOrder builds a nearest neighbour path and improves it with 2-opt
+// until no reversal shortens it.
+func heuristicOrder(start RoutePoint, stops []RoutePoint, end RoutePoint) []int {
+	n := len(stops)
+	visited := make([]bool, n)
+	order := make([]int, 0, n)
+
+	current := start
+	for len(order) < n {
+		next, best := -1, math.Inf(1)
+		for j := 0; j < n; j++ {
+			if visited[j] {
+				continue
+			}
+			if d := distance(current, stops[j]); d < best {
+				next, best = j, d
+			}
+		}
+		visited[next] = true
+		order = append(order, next)
+		current = stops[next]
+	}
+
+	// point returns the i-th point of the full path where 0 is start and
+	// n+1 is end, so that the fixed endpoints are never reversed.
+	point := func(i int) RoutePoint {
+		switch {
+		case i == 0:
+			return start
+		case i == n+1:
+			return end
+		default:
+			return stops[order[i-1]]
+		}
+	}
+
+	for improved := true; improved; {
+		improved = false
+		for i := 1; i < n; i++ {
+			for k := i + 1; k <= n; k++ {
+				before := distance(point(i-1), point(i)) + distance(point(k), point(k+1))
+				after := distance(point(i-1), point(k)) + distance(point(i), point(k+1))
+				if after < before-1e-9 {
+					for l, r := i-1, k-1; l < r; l, r = l+1, r-1 {
+						order[l], order[r] = order[r], order[l]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+
+	return order
+}
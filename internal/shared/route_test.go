@@ -0,0 +1,137 @@
+package shared
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// bruteForceTotal tries every visiting order of stops.
+func bruteForceTotal(start RoutePoint, stops []RoutePoint, end RoutePoint) float64 {
+	order := make([]int, len(stops))
+	for i := range order {
+		order[i] = i
+	}
+
+	best := math.Inf(1)
+	var permute func(k int)
+	permute = func(k int) {
+		if k == len(order) {
+			total, prev := 0.0, start
+			for _, idx := range order {
+				total += distance(prev, stops[idx])
+				prev = stops[idx]
+			}
+			best = math.Min(best, total+distance(prev, end))
+			return
+		}
+		for i := k; i < len(order); i++ {
+			order[k], order[i] = order[i], order[k]
+			permute(k + 1)
+			order[k], order[i] = order[i], order[k]
+		}
+	}
+	permute(0)
+	return best
+}
+
+// randomPoints scatters n points within a few km of central Jakarta.
+func randomPoints(rng *rand.Rand, n int) []RoutePoint {
+	points := make([]RoutePoint, n)
+	for i := range points {
+		points[i] = RoutePoint{Lat: -6.2 + rng.Float64()*0.05, Long: 106.8 + rng.Float64()*0.05}
+	}
+	return points
+}
+
+// checkRoute verifies that route visits every stop once, leaves start and
+// finishes at end.
+func checkRoute(t *testing.T, route Route, start RoutePoint, stops []RoutePoint, end RoutePoint) {
+	t.Helper()
+
+	if len(route.Order) != len(stops) {
+		t.Fatalf("order has %d stops, want %d", len(route.Order), len(stops))
+	}
+	seen := make([]bool, len(stops))
+	for _, idx := range route.Order {
+		if idx < 0 || idx >= len(stops) || seen[idx] {
+			t.Fatalf("order %v is not a permutation of the stops", route.Order)
+		}
+		seen[idx] = true
+	}
+	if len(route.Legs) != len(stops)+1 {
+		t.Fatalf("got %d legs, want %d", len(route.Legs), len(stops)+1)
+	}
+
+	first, last := end, start
+	if len(stops) > 0 {
+		first, last = stops[route.Order[0]], stops[route.Order[len(stops)-1]]
+	}
+	if got, want := route.Legs[0], distance(start, first); math.Abs(got-want) > 1e-9 {
+		t.Errorf("first leg is %f km, want %f km from the start", got, want)
+	}
+	if got, want := route.Legs[len(stops)], distance(last, end); math.Abs(got-want) > 1e-9 {
+		t.Errorf("last leg is %f km, want %f km to the user", got, want)
+	}
+
+	sum := 0.0
+	for _, leg := range route.Legs {
+		sum += leg
+	}
+	if math.Abs(sum-route.Total) > 1e-9 {
+		t.Errorf("legs add up to %f km, total is %f km", sum, route.Total)
+	}
+}
+
+func TestPlanRouteMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for stops := 0; stops <= 8; stops++ {
+		for round := 0; round < 5; round++ {
+			points := randomPoints(rng, stops+2)
+			start, end, rest := points[0], points[1], points[2:]
+
+			route := PlanRoute(start, rest, end)
+			checkRoute(t, route, start, rest, end)
+			if want := bruteForceTotal(start, rest, end); math.Abs(route.Total-want) > 1e-9 {
+				t.Errorf("%d stops, round %d: total %f km, brute force finds %f km", stops, round, route.Total, want)
+			}
+		}
+	}
+}
+
+func TestPlanRouteKeepsEndpoints(t *testing.T) {
+	// The start and the user lie between the stops, so a route free to pick
+	// its own endpoints would start and end elsewhere
+	start := RoutePoint{Lat: -6.2, Long: 106.80}
+	end := RoutePoint{Lat: -6.2, Long: 106.81}
+	tests := []struct {
+		name  string
+		stops []RoutePoint
+	}{
+		{"no stops", nil},
+		{"one stop", []RoutePoint{{Lat: -6.2, Long: 106.85}}},
+		{"stops on both sides", []RoutePoint{
+			{Lat: -6.2, Long: 106.75},
+			{Lat: -6.2, Long: 106.85},
+			{Lat: -6.2, Long: 106.78},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := PlanRoute(start, tt.stops, end)
+			checkRoute(t, route, start, tt.stops, end)
+			if want := bruteForceTotal(start, tt.stops, end); math.Abs(route.Total-want) > 1e-9 {
+				t.Errorf("total %f km, brute force finds %f km", route.Total, want)
+			}
+		})
+	}
+}
+
+func TestPlanRouteHeuristic(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	points := randomPoints(rng, ExactRouteMaxStops+6)
+	start, end, rest := points[0], points[1], points[2:]
+
+	route := PlanRoute(start, rest, end)
+	checkRoute(t, route, start, rest, end)
+}
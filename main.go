@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/config"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/events"
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/handlers"
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/middleware"
+	"github.com/ProjectSprint-Generalist/BeliMang/internal/payment"
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/routes"
 	"github.com/ProjectSprint-Generalist/BeliMang/internal/storage"
 	"github.com/gin-gonic/gin"
@@ -58,9 +60,21 @@ func setupGin(cfg *config.Config, pool *pgxpool.Pool, minioClient *storage.Minio
 	userHandler := handlers.NewUserHandler(pool)
 	merchantHandler := handlers.NewMerchantHandler(pool)
 	imageHandler := handlers.NewImageHandler(pool, minioClient)
-	estimateHandler := handlers.NewEstimateHandler(pool)
-	orderHandler := handlers.NewOrderHandler(pool)
-	routes.SetupRoutes(router, adminHandler, userHandler, merchantHandler, imageHandler, estimateHandler, orderHandler)
+	estimateHandler := handlers.NewEstimateHandler(pool, &cfg.Estimate)
+	payments := setupPaymentProvider(cfg)
+	orderHandler := handlers.NewOrderHandler(pool, &cfg.Estimate, &cfg.Order, payments)
+	zoneHandler := handlers.NewZoneHandler(pool)
+
+	// Order events reach clients through LISTEN/NOTIFY
+	broker := events.NewBroker(pool)
+	go broker.Run(context.Background())
+	eventHandler := handlers.NewEventHandler(pool, broker, &cfg.Events)
+	courierHandler := handlers.NewCourierHandler(pool)
+	walletHandler := handlers.NewWalletHandler(pool)
+	paymentHandler := handlers.NewPaymentHandler(pool, payments)
+	go paymentHandler.ExpirePayments(context.Background(), cfg.Payment.Timeout)
+
+	routes.SetupRoutes(router, adminHandler, userHandler, merchantHandler, imageHandler, estimateHandler, orderHandler, zoneHandler, eventHandler, courierHandler, walletHandler, paymentHandler)
 
 	port := cfg.Port
 	if port == "" {
@@ -70,6 +84,24 @@ func setupGin(cfg *config.Config, pool *pgxpool.Pool, minioClient *storage.Minio
 	return router
 }
 
+func setupPaymentProvider(cfg *config.Config) payment.PaymentProvider {
+	if cfg.Environment == "production" && cfg.Payment.WebhookSecret == config.DefaultPaymentWebhookSecret {
+		log.Fatal().Msg("PAYMENT_WEBHOOK_SECRET must be set in production")
+	}
+
+	switch cfg.Payment.Provider {
+	case "fake":
+		// Nothing is charged and anyone could forge its payments
+		if cfg.Environment == "production" {
+			log.Fatal().Msg("The fake payment provider cannot be used in production, set PAYMENT_PROVIDER")
+		}
+		return payment.NewFakeProvider(cfg.Payment.WebhookSecret)
+	default:
+		log.Fatal().Msgf("Unknown payment provider: %s", cfg.Payment.Provider)
+		return nil
+	}
+}
+
 func setupDatabase(cfg *config.Config) *pgxpool.Pool {
 	ctx := context.Background()
 
@@ -0,0 +1,14 @@
+-- Per merchant category settings used by the estimate pipeline
+CREATE TABLE IF NOT EXISTS merchant_category_settings (
+  merchant_category merchant_category PRIMARY KEY,
+  prep_time_minutes INTEGER NOT NULL CHECK (prep_time_minutes >= 0)
+);
+
+INSERT INTO merchant_category_settings (merchant_category, prep_time_minutes) VALUES
+  ('SmallRestaurant', 15),
+  ('MediumRestaurant', 20),
+  ('LargeRestaurant', 25),
+  ('MerchandiseRestaurant', 10),
+  ('BoothKiosk', 5),
+  ('ConvenienceStore', 5)
+ON CONFLICT (merchant_category) DO NOTHING;
@@ -0,0 +1,6 @@
+ALTER TABLE merchant_category_settings
+  DROP COLUMN IF EXISTS base_delivery_fee,
+  DROP COLUMN IF EXISTS per_km_fee,
+  DROP COLUMN IF EXISTS small_order_threshold,
+  DROP COLUMN IF EXISTS small_order_surcharge,
+  DROP COLUMN IF EXISTS service_fee_percent;
@@ -0,0 +1,8 @@
+-- Delivery fee schedule per merchant category. Surcharge and service fee are
+-- optional and disabled when zero.
+ALTER TABLE merchant_category_settings
+  ADD COLUMN IF NOT EXISTS base_delivery_fee INTEGER NOT NULL DEFAULT 5000 CHECK (base_delivery_fee >= 0),
+  ADD COLUMN IF NOT EXISTS per_km_fee INTEGER NOT NULL DEFAULT 2500 CHECK (per_km_fee >= 0),
+  ADD COLUMN IF NOT EXISTS small_order_threshold INTEGER NOT NULL DEFAULT 0 CHECK (small_order_threshold >= 0),
+  ADD COLUMN IF NOT EXISTS small_order_surcharge INTEGER NOT NULL DEFAULT 0 CHECK (small_order_surcharge >= 0),
+  ADD COLUMN IF NOT EXISTS service_fee_percent INTEGER NOT NULL DEFAULT 0 CHECK (service_fee_percent BETWEEN 0 AND 100);
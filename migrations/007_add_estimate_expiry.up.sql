@@ -0,0 +1,10 @@
+-- Calculated estimates can only be ordered until they expire
+ALTER TABLE calculated_estimates
+  ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+UPDATE calculated_estimates
+SET expires_at = created_at + INTERVAL '15 minutes'
+WHERE expires_at IS NULL;
+
+ALTER TABLE calculated_estimates
+  ALTER COLUMN expires_at SET NOT NULL;
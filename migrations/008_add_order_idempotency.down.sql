@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS order_idempotency_keys;
+DROP INDEX IF EXISTS idx_orders_calculated_estimate_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS duplicate_of;
@@ -0,0 +1,26 @@
+-- Estimates ordered more than once before this migration keep all their
+-- orders. The earliest stays the estimate's order, the later ones point at it
+-- through duplicate_of and are left out of the unique index below.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS duplicate_of UUID REFERENCES orders(id);
+UPDATE orders o
+SET duplicate_of = earliest.id
+FROM (
+  SELECT DISTINCT ON (calculated_estimate_id) id, calculated_estimate_id
+  FROM orders
+  ORDER BY calculated_estimate_id, created_at, id
+) earliest
+WHERE o.calculated_estimate_id = earliest.calculated_estimate_id AND o.id <> earliest.id;
+
+-- A calculated estimate can be turned into at most one order
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_calculated_estimate_id ON orders (calculated_estimate_id)
+WHERE duplicate_of IS NULL;
+
+-- Idempotency keys sent with POST /users/orders, scoped per user
+CREATE TABLE IF NOT EXISTS order_idempotency_keys (
+  user_id UUID NOT NULL REFERENCES users(id),
+  idempotency_key TEXT NOT NULL,
+  request_hash TEXT NOT NULL,
+  order_id UUID NOT NULL REFERENCES orders(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, idempotency_key)
+);
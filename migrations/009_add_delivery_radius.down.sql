@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS merchant_can_deliver(GEOMETRY, INTEGER, GEOMETRY);
+DROP TABLE IF EXISTS delivery_zones;
+ALTER TABLE merchants DROP COLUMN IF EXISTS delivery_radius_m;
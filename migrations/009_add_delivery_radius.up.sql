@@ -0,0 +1,37 @@
+-- Delivery radius per merchant, in meters
+ALTER TABLE merchants
+  ADD COLUMN IF NOT EXISTS delivery_radius_m INTEGER NOT NULL DEFAULT 3000 CHECK (delivery_radius_m > 0);
+
+-- Delivery zones, e.g. one per city. A merchant located inside a zone follows
+-- the zone's rules instead of its own radius.
+CREATE TABLE IF NOT EXISTS delivery_zones (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL,
+  area GEOMETRY(POLYGON, 4326) NOT NULL CHECK (ST_IsValid(area)),
+  delivery_radius_m INTEGER NOT NULL CHECK (delivery_radius_m > 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_delivery_zones_area ON delivery_zones USING GIST (area);
+
+-- merchant_can_deliver reports whether a merchant can deliver to target. When
+-- the merchant is inside a zone (the smallest one if zones overlap) the target
+-- must be in that zone and within the zone's radius. Otherwise the merchant's
+-- own radius applies.
+CREATE OR REPLACE FUNCTION merchant_can_deliver(
+  merchant_location GEOMETRY,
+  merchant_radius_m INTEGER,
+  target GEOMETRY
+) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+  SELECT COALESCE(
+    (
+      SELECT ST_Covers(z.area, target)
+        AND ST_DistanceSphere(merchant_location, target) <= z.delivery_radius_m
+      FROM delivery_zones z
+      WHERE ST_Covers(z.area, merchant_location)
+      ORDER BY ST_Area(z.area) ASC
+      LIMIT 1
+    ),
+    ST_DistanceSphere(merchant_location, target) <= merchant_radius_m
+  );
+$$;
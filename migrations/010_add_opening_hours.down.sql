@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS merchant_holidays;
+DROP TABLE IF EXISTS merchant_opening_hours;
+ALTER TABLE merchants DROP COLUMN IF EXISTS time_zone;
@@ -0,0 +1,22 @@
+-- IANA time zone the merchant's opening hours and holidays are expressed in
+ALTER TABLE merchants
+  ADD COLUMN IF NOT EXISTS time_zone TEXT NOT NULL DEFAULT 'Asia/Jakarta';
+
+-- Weekly opening hours. day_of_week follows Go's time.Weekday (0 = Sunday).
+-- A window that closes at or before it opens runs past midnight. Merchants
+-- without any row are open all week.
+CREATE TABLE IF NOT EXISTS merchant_opening_hours (
+  merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+  day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+  opens_at TIME NOT NULL,
+  closes_at TIME NOT NULL CHECK (closes_at <> opens_at),
+  PRIMARY KEY (merchant_id, day_of_week, opens_at)
+);
+
+-- Local dates on which the merchant stays closed whatever its weekly hours
+CREATE TABLE IF NOT EXISTS merchant_holidays (
+  merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+  holiday_date DATE NOT NULL,
+  note TEXT,
+  PRIMARY KEY (merchant_id, holiday_date)
+);
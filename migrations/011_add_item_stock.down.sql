@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS order_item_reservations;
+ALTER TABLE merchant_items
+  DROP COLUMN IF EXISTS stock,
+  DROP COLUMN IF EXISTS available;
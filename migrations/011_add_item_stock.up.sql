@@ -0,0 +1,16 @@
+-- Availability toggle and optional tracked stock per item. A NULL stock is
+-- not tracked and never runs out.
+ALTER TABLE merchant_items
+  ADD COLUMN IF NOT EXISTS available BOOLEAN NOT NULL DEFAULT TRUE,
+  ADD COLUMN IF NOT EXISTS stock INTEGER CHECK (stock >= 0);
+
+-- Stock taken by an order for items whose stock is tracked. released_at is
+-- set once the stock has been given back, e.g. when the order is cancelled.
+CREATE TABLE IF NOT EXISTS order_item_reservations (
+  order_id UUID NOT NULL REFERENCES orders(id),
+  item_id UUID NOT NULL REFERENCES merchant_items(id),
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  released_at TIMESTAMPTZ,
+  PRIMARY KEY (order_id, item_id)
+);
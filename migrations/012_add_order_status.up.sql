@@ -0,0 +1,31 @@
+-- Order lifecycle. Allowed transitions are enforced by the application.
+CREATE TYPE order_status AS ENUM (
+  'placed',
+  'accepted',
+  'preparing',
+  'picked_up',
+  'delivered',
+  'cancelled',
+  'rejected'
+);
+
+ALTER TABLE orders
+  ADD COLUMN IF NOT EXISTS status order_status NOT NULL DEFAULT 'placed';
+
+-- Every status change. from_status is NULL for the initial status, actor_id
+-- and actor_role are NULL for changes made by the system.
+CREATE TABLE IF NOT EXISTS order_status_history (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id UUID NOT NULL REFERENCES orders(id),
+  from_status order_status,
+  to_status order_status NOT NULL,
+  actor_id UUID REFERENCES users(id),
+  actor_role user_role,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_id ON order_status_history (order_id, created_at);
+
+-- Existing orders were placed by their user
+INSERT INTO order_status_history (order_id, to_status, actor_id, actor_role, created_at)
+SELECT id, 'placed', user_id, 'user', created_at
+FROM orders;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_merchants;
+DROP TYPE IF EXISTS order_merchant_status;
@@ -0,0 +1,52 @@
+-- Status of one merchant's part of an order
+CREATE TYPE order_merchant_status AS ENUM (
+  'pending',
+  'accepted',
+  'ready',
+  'rejected'
+);
+
+-- The merchants of an order. position is the merchant's place on the route.
+CREATE TABLE IF NOT EXISTS order_merchants (
+  order_id UUID NOT NULL REFERENCES orders(id),
+  merchant_id UUID NOT NULL REFERENCES merchants(id),
+  position INTEGER NOT NULL,
+  subtotal INTEGER NOT NULL,
+  status order_merchant_status NOT NULL DEFAULT 'pending',
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (order_id, merchant_id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_merchants_merchant_id ON order_merchants (merchant_id, status);
+
+-- Backfill from the stored estimates. The sub-status follows the order status.
+-- Estimates stored before timelines existed have no subtotal, it is computed
+-- from their items at the current catalog prices since the prices they were
+-- ordered at were not kept.
+INSERT INTO order_merchants (order_id, merchant_id, position, subtotal, status)
+SELECT
+  o.id,
+  (m.value->>'merchantId')::uuid,
+  m.ordinality - 1,
+  COALESCE((
+    SELECT (s->>'subtotal')::int
+    FROM jsonb_array_elements(ce.estimate_data->'timeline'->'stops') AS s
+    WHERE (s->>'merchantId')::uuid = (m.value->>'merchantId')::uuid
+    LIMIT 1
+  ), (
+    SELECT SUM(mi.price * (i->>'quantity')::int)::int
+    FROM jsonb_array_elements(m.value->'items') AS i
+    JOIN merchant_items mi ON mi.id = (i->>'itemId')::uuid
+  ), 0),
+  CASE o.status
+    WHEN 'accepted' THEN 'accepted'
+    WHEN 'preparing' THEN 'accepted'
+    WHEN 'picked_up' THEN 'ready'
+    WHEN 'delivered' THEN 'ready'
+    WHEN 'rejected' THEN 'rejected'
+    ELSE 'pending'
+  END::order_merchant_status
+FROM orders o
+JOIN calculated_estimates ce ON ce.id = o.calculated_estimate_id
+CROSS JOIN LATERAL jsonb_array_elements(ce.estimate_data->'orders') WITH ORDINALITY AS m(value, ordinality)
+WHERE EXISTS (SELECT 1 FROM merchants WHERE id = (m.value->>'merchantId')::uuid)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,10 @@
+-- Cancellations requested by users. fee is kept from total_price and
+-- refund_amount is what goes back to the user.
+CREATE TABLE IF NOT EXISTS order_cancellations (
+  order_id UUID PRIMARY KEY REFERENCES orders(id),
+  reason TEXT,
+  fee INTEGER NOT NULL CHECK (fee >= 0),
+  refund_amount INTEGER NOT NULL CHECK (refund_amount >= 0),
+  cancelled_by UUID NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,29 @@
+-- The items of each merchant's part of an order. position keeps the order in
+-- which they were listed.
+CREATE TABLE IF NOT EXISTS order_items (
+  order_id UUID NOT NULL,
+  merchant_id UUID NOT NULL,
+  item_id UUID NOT NULL REFERENCES merchant_items(id),
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  position INTEGER NOT NULL,
+  PRIMARY KEY (order_id, merchant_id, position),
+  FOREIGN KEY (order_id, merchant_id) REFERENCES order_merchants(order_id, merchant_id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_items_item_id ON order_items (item_id);
+CREATE INDEX IF NOT EXISTS idx_orders_user_id_created_at ON orders (user_id, created_at DESC);
+
+-- Backfill from the stored estimates
+INSERT INTO order_items (order_id, merchant_id, item_id, quantity, position)
+SELECT
+  om.order_id,
+  om.merchant_id,
+  (i.value->>'itemId')::uuid,
+  (i.value->>'quantity')::int,
+  i.ordinality - 1
+FROM orders o
+JOIN calculated_estimates ce ON ce.id = o.calculated_estimate_id
+CROSS JOIN LATERAL jsonb_array_elements(ce.estimate_data->'orders') AS m(value)
+JOIN order_merchants om ON om.order_id = o.id AND om.merchant_id = (m.value->>'merchantId')::uuid
+CROSS JOIN LATERAL jsonb_array_elements(m.value->'items') WITH ORDINALITY AS i(value, ordinality)
+WHERE EXISTS (SELECT 1 FROM merchant_items WHERE id = (i.value->>'itemId')::uuid)
+ON CONFLICT DO NOTHING;
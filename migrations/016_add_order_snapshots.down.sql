@@ -0,0 +1,13 @@
+ALTER TABLE order_items
+  DROP COLUMN IF EXISTS item_created_at,
+  DROP COLUMN IF EXISTS image_url,
+  DROP COLUMN IF EXISTS unit_price,
+  DROP COLUMN IF EXISTS product_category,
+  DROP COLUMN IF EXISTS name;
+
+ALTER TABLE order_merchants
+  DROP COLUMN IF EXISTS merchant_created_at,
+  DROP COLUMN IF EXISTS location,
+  DROP COLUMN IF EXISTS image_url,
+  DROP COLUMN IF EXISTS merchant_category,
+  DROP COLUMN IF EXISTS name;
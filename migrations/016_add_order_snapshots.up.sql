@@ -0,0 +1,50 @@
+-- Merchant and item details as they were when the order was placed, so that
+-- later catalog edits don't rewrite order history
+ALTER TABLE order_merchants
+  ADD COLUMN IF NOT EXISTS name TEXT,
+  ADD COLUMN IF NOT EXISTS merchant_category merchant_category,
+  ADD COLUMN IF NOT EXISTS image_url TEXT,
+  ADD COLUMN IF NOT EXISTS location GEOMETRY(POINT, 4326),
+  ADD COLUMN IF NOT EXISTS merchant_created_at TIMESTAMPTZ;
+
+ALTER TABLE order_items
+  ADD COLUMN IF NOT EXISTS name TEXT,
+  ADD COLUMN IF NOT EXISTS product_category product_category,
+  ADD COLUMN IF NOT EXISTS unit_price INTEGER,
+  ADD COLUMN IF NOT EXISTS image_url TEXT,
+  ADD COLUMN IF NOT EXISTS item_created_at TIMESTAMPTZ;
+
+-- Existing orders get the current details, the best that is left
+UPDATE order_merchants om
+SET
+  name = m.name,
+  merchant_category = m.merchant_category,
+  image_url = m.image_url,
+  location = m.location,
+  merchant_created_at = m.created_at
+FROM merchants m
+WHERE m.id = om.merchant_id AND om.name IS NULL;
+
+UPDATE order_items oi
+SET
+  name = mi.name,
+  product_category = mi.product_category,
+  unit_price = mi.price,
+  image_url = mi.image_url,
+  item_created_at = mi.created_at
+FROM merchant_items mi
+WHERE mi.id = oi.item_id AND oi.name IS NULL;
+
+ALTER TABLE order_merchants
+  ALTER COLUMN name SET NOT NULL,
+  ALTER COLUMN merchant_category SET NOT NULL,
+  ALTER COLUMN image_url SET NOT NULL,
+  ALTER COLUMN location SET NOT NULL,
+  ALTER COLUMN merchant_created_at SET NOT NULL;
+
+ALTER TABLE order_items
+  ALTER COLUMN name SET NOT NULL,
+  ALTER COLUMN product_category SET NOT NULL,
+  ALTER COLUMN unit_price SET NOT NULL,
+  ALTER COLUMN image_url SET NOT NULL,
+  ALTER COLUMN item_created_at SET NOT NULL;
@@ -0,0 +1,35 @@
+-- Events streamed to clients watching an order. id orders the events and is
+-- what clients resume from.
+CREATE TABLE IF NOT EXISTS order_events (
+  id BIGSERIAL PRIMARY KEY,
+  order_id UUID NOT NULL REFERENCES orders(id),
+  type TEXT NOT NULL,
+  data JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_order_events_order_id ON order_events (order_id, id);
+
+-- Existing status changes can be replayed too
+INSERT INTO order_events (order_id, type, data, created_at)
+SELECT order_id, 'status', jsonb_build_object('from', from_status, 'status', to_status), created_at
+FROM order_status_history
+ORDER BY created_at;
+
+-- Every replica listens on order_events. NOTIFY is only delivered once the
+-- inserting transaction commits.
+CREATE OR REPLACE FUNCTION notify_order_event() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('order_events', json_build_object(
+    'id', NEW.id,
+    'orderId', NEW.order_id,
+    'type', NEW.type,
+    'data', NEW.data,
+    'createdAt', NEW.created_at
+  )::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER order_events_notify
+AFTER INSERT ON order_events
+FOR EACH ROW EXECUTE FUNCTION notify_order_event();
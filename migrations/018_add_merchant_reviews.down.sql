@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS merchant_reviews;
+
+ALTER TABLE merchants
+  DROP COLUMN IF EXISTS rating_count,
+  DROP COLUMN IF EXISTS rating_sum;
@@ -0,0 +1,18 @@
+-- Running totals, the average is rating_sum / rating_count
+ALTER TABLE merchants
+  ADD COLUMN IF NOT EXISTS rating_sum INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+
+-- One review per merchant of a delivered order
+CREATE TABLE IF NOT EXISTS merchant_reviews (
+  order_id UUID NOT NULL,
+  merchant_id UUID NOT NULL,
+  user_id UUID NOT NULL REFERENCES users(id),
+  rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+  comment TEXT,
+  image_url TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (order_id, merchant_id),
+  FOREIGN KEY (order_id, merchant_id) REFERENCES order_merchants(order_id, merchant_id)
+);
+CREATE INDEX IF NOT EXISTS idx_merchant_reviews_merchant_id ON merchant_reviews (merchant_id, created_at DESC);
@@ -0,0 +1,11 @@
+-- Postgres cannot drop an enum value, the type is rebuilt without it
+UPDATE order_status_history SET actor_id = NULL, actor_role = NULL WHERE actor_role = 'courier';
+DELETE FROM users WHERE role = 'courier';
+
+ALTER TYPE user_role RENAME TO user_role_old;
+CREATE TYPE user_role AS ENUM ('user', 'admin');
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE user_role USING role::text::user_role;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+ALTER TABLE order_status_history ALTER COLUMN actor_role TYPE user_role USING actor_role::text::user_role;
+DROP TYPE user_role_old;
@@ -0,0 +1,3 @@
+-- A new enum value cannot be used in the transaction that adds it, so the
+-- courier tables follow in the next migration
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'courier';
@@ -0,0 +1,9 @@
+ALTER TABLE order_merchants
+  DROP COLUMN IF EXISTS picked_up_at;
+
+DROP INDEX IF EXISTS idx_orders_courier_id;
+ALTER TABLE orders
+  DROP COLUMN IF EXISTS courier_accepted_at,
+  DROP COLUMN IF EXISTS courier_id;
+
+DROP TABLE IF EXISTS couriers;
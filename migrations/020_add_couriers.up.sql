@@ -0,0 +1,19 @@
+-- Couriers are users with the courier role. location is their last reported
+-- position, only available couriers are assigned orders.
+CREATE TABLE IF NOT EXISTS couriers (
+  user_id UUID PRIMARY KEY REFERENCES users(id),
+  available BOOLEAN NOT NULL DEFAULT FALSE,
+  location GEOMETRY(POINT, 4326),
+  location_updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_couriers_location ON couriers USING GIST (location) WHERE available;
+
+-- courier_id is set on assignment, courier_accepted_at once the courier
+-- accepts the order
+ALTER TABLE orders
+  ADD COLUMN IF NOT EXISTS courier_id UUID REFERENCES users(id),
+  ADD COLUMN IF NOT EXISTS courier_accepted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_orders_courier_id ON orders (courier_id, status);
+
+ALTER TABLE order_merchants
+  ADD COLUMN IF NOT EXISTS picked_up_at TIMESTAMPTZ;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS courier_locations;
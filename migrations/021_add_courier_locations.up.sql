@@ -0,0 +1,8 @@
+-- GPS pings sent by couriers. couriers.location keeps the latest one.
+CREATE TABLE IF NOT EXISTS courier_locations (
+  id BIGSERIAL PRIMARY KEY,
+  courier_id UUID NOT NULL REFERENCES users(id),
+  location GEOMETRY(POINT, 4326) NOT NULL,
+  recorded_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_courier_locations_courier_id ON courier_locations (courier_id, recorded_at);
@@ -0,0 +1,63 @@
+-- Double-entry ledger. Every transaction moves money between accounts with
+-- entries summing to zero, an account's balance is the sum of its entries.
+-- Each user has one wallet account; top_up is where admin credits come
+-- from and orders holds what was paid for orders.
+CREATE TYPE ledger_account_type AS ENUM (
+  'wallet',
+  'top_up',
+  'orders'
+);
+
+CREATE TYPE ledger_transaction_kind AS ENUM (
+  'top_up',
+  'order_payment',
+  'order_refund'
+);
+
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  type ledger_account_type NOT NULL,
+  user_id UUID UNIQUE REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CHECK ((type = 'wallet') = (user_id IS NOT NULL))
+);
+-- One account of each system type
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_system_type ON ledger_accounts (type) WHERE user_id IS NULL;
+
+INSERT INTO ledger_accounts (type) VALUES ('top_up'), ('orders')
+ON CONFLICT DO NOTHING;
+
+-- created_by is the admin crediting a top-up, NULL otherwise
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  kind ledger_transaction_kind NOT NULL,
+  order_id UUID REFERENCES orders(id),
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- An order is paid and refunded at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_transactions_order_id_kind ON ledger_transactions (order_id, kind) WHERE order_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+  id BIGSERIAL PRIMARY KEY,
+  transaction_id UUID NOT NULL REFERENCES ledger_transactions(id),
+  account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+  amount BIGINT NOT NULL CHECK (amount <> 0)
+);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account_id ON ledger_entries (account_id, id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_transaction_id ON ledger_entries (transaction_id);
+
+-- Checked at commit, once all entries of the transaction are in
+CREATE OR REPLACE FUNCTION check_ledger_balanced() RETURNS trigger AS $$
+BEGIN
+  IF (SELECT SUM(amount) FROM ledger_entries WHERE transaction_id = NEW.transaction_id) <> 0 THEN
+    RAISE EXCEPTION 'ledger transaction % is not balanced', NEW.transaction_id;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced
+AFTER INSERT ON ledger_entries
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION check_ledger_balanced();
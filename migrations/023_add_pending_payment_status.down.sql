@@ -0,0 +1,21 @@
+-- Postgres cannot drop an enum value, the type is rebuilt without it. Unpaid
+-- orders never reached a merchant and are dropped as cancelled.
+UPDATE orders SET status = 'cancelled' WHERE status = 'pending_payment';
+DELETE FROM order_status_history WHERE from_status = 'pending_payment' OR to_status = 'pending_payment';
+
+ALTER TYPE order_status RENAME TO order_status_old;
+CREATE TYPE order_status AS ENUM (
+  'placed',
+  'accepted',
+  'preparing',
+  'picked_up',
+  'delivered',
+  'cancelled',
+  'rejected'
+);
+ALTER TABLE orders ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE orders ALTER COLUMN status TYPE order_status USING status::text::order_status;
+ALTER TABLE orders ALTER COLUMN status SET DEFAULT 'placed';
+ALTER TABLE order_status_history ALTER COLUMN from_status TYPE order_status USING from_status::text::order_status;
+ALTER TABLE order_status_history ALTER COLUMN to_status TYPE order_status USING to_status::text::order_status;
+DROP TYPE order_status_old;
@@ -0,0 +1,4 @@
+-- Orders paid through a payment provider wait in pending_payment until the
+-- payment is captured. Added on its own, a new enum value cannot be used in
+-- the transaction that adds it.
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'pending_payment' BEFORE 'placed';
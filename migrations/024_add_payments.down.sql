@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS payment_webhook_events;
+DROP TABLE IF EXISTS payments;
+DROP TYPE IF EXISTS payment_status;
@@ -0,0 +1,37 @@
+CREATE TYPE payment_status AS ENUM (
+  'pending',
+  'authorized',
+  'captured',
+  'failed',
+  'cancelled',
+  'refund_pending',
+  'refunded'
+);
+
+-- Orders paid through a payment provider. Wallet payments live in the
+-- ledger instead. The provider intent is created once the order committed,
+-- intent_id and client_secret are null until then. refund_amount is set once
+-- a refund is owed.
+CREATE TABLE IF NOT EXISTS payments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id UUID NOT NULL UNIQUE REFERENCES orders(id),
+  provider TEXT NOT NULL,
+  method TEXT NOT NULL,
+  intent_id TEXT,
+  client_secret TEXT,
+  amount INTEGER NOT NULL CHECK (amount > 0),
+  status payment_status NOT NULL DEFAULT 'pending',
+  refund_amount INTEGER CHECK (refund_amount >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (provider, intent_id)
+);
+
+-- Webhook events already handled, providers deliver at least once
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+  provider TEXT NOT NULL,
+  event_id TEXT NOT NULL,
+  type TEXT NOT NULL,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (provider, event_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE merchant_items DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE merchants DROP COLUMN IF EXISTS deleted_at;
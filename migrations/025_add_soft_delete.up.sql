@@ -0,0 +1,4 @@
+-- Deleted merchants and items leave the catalog but keep their rows, orders
+-- and reviews still reference them and admins can restore them
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE merchant_items ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
@@ -0,0 +1,36 @@
+-- Option groups such as size or toppings. A group is required when at least
+-- one option must be chosen from it.
+CREATE TABLE IF NOT EXISTS item_option_groups (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  item_id UUID NOT NULL REFERENCES merchant_items(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  min_selections INTEGER NOT NULL DEFAULT 0 CHECK (min_selections >= 0),
+  max_selections INTEGER NOT NULL CHECK (max_selections >= 1 AND max_selections >= min_selections),
+  position INTEGER NOT NULL,
+  UNIQUE (item_id, position)
+);
+
+-- price_delta is added to the item price once per unit
+CREATE TABLE IF NOT EXISTS item_options (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  group_id UUID NOT NULL REFERENCES item_option_groups(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  price_delta INTEGER NOT NULL DEFAULT 0 CHECK (price_delta >= 0),
+  position INTEGER NOT NULL,
+  UNIQUE (group_id, position)
+);
+
+-- Options chosen for an ordered item, copied at order time. option_id has no
+-- foreign key so options can be replaced without touching order history.
+CREATE TABLE IF NOT EXISTS order_item_options (
+  order_id UUID NOT NULL,
+  merchant_id UUID NOT NULL,
+  item_position INTEGER NOT NULL,
+  position INTEGER NOT NULL,
+  option_id UUID NOT NULL,
+  group_name TEXT NOT NULL,
+  name TEXT NOT NULL,
+  price_delta INTEGER NOT NULL,
+  PRIMARY KEY (order_id, merchant_id, item_position, position),
+  FOREIGN KEY (order_id, merchant_id, item_position) REFERENCES order_items(order_id, merchant_id, position)
+);
@@ -0,0 +1,35 @@
+-- name: RecordCourierLocation :one
+-- Also moves the courier's latest position used for assignment
+WITH ping AS (
+  INSERT INTO courier_locations (courier_id, location)
+  VALUES (sqlc.arg(courier_id), ST_SetSRID(ST_MakePoint(sqlc.arg(long)::float8, sqlc.arg(lat)::float8), 4326))
+  RETURNING courier_id, location, recorded_at
+)
+UPDATE couriers c
+SET location = ping.location, location_updated_at = ping.recorded_at
+FROM ping
+WHERE c.user_id = ping.courier_id
+RETURNING ping.recorded_at;
+
+-- name: GetCourierLocationsSince :many
+SELECT
+  ST_Y(location)::float8 AS lat,
+  ST_X(location)::float8 AS long,
+  recorded_at
+FROM courier_locations
+WHERE courier_id = $1 AND recorded_at >= $2
+ORDER BY recorded_at;
+
+-- name: GetOrderCourierPosition :one
+-- The latest position of the courier assigned to the order
+SELECT
+  o.courier_id,
+  ST_Y(c.location)::float8 AS lat,
+  ST_X(c.location)::float8 AS long,
+  c.location_updated_at,
+  (ce.estimate_data->'userLocation'->>'lat')::float8 AS user_lat,
+  (ce.estimate_data->'userLocation'->>'long')::float8 AS user_long
+FROM orders o
+JOIN calculated_estimates ce ON ce.id = o.calculated_estimate_id
+JOIN couriers c ON c.user_id = o.courier_id
+WHERE o.id = $1 AND c.location IS NOT NULL;
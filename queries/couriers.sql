@@ -0,0 +1,114 @@
+-- name: CreateCourier :exec
+WITH courier_user AS (
+  INSERT INTO users (
+    username, password, email, role
+  ) VALUES (
+    $1, $2, $3, 'courier'
+  ) RETURNING id
+)
+INSERT INTO couriers (user_id)
+SELECT id FROM courier_user;
+
+-- name: SetCourierAvailability :exec
+-- A missing location keeps the last reported one
+UPDATE couriers
+SET available = sqlc.arg(available),
+  location = COALESCE(ST_SetSRID(ST_MakePoint(sqlc.narg(long)::float8, sqlc.narg(lat)::float8), 4326), location),
+  location_updated_at = CASE WHEN sqlc.narg(lat)::float8 IS NULL THEN location_updated_at ELSE CURRENT_TIMESTAMP END
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: AssignNearestCourier :exec
+-- Assigns the order to the available courier without an active order that is
+-- closest to the first stop of the route. Couriers being assigned by a
+-- concurrent transaction are skipped.
+WITH pickup AS (
+  SELECT location FROM order_merchants
+  WHERE order_id = sqlc.arg(order_id) AND position = 0
+), courier AS (
+  SELECT c.user_id
+  FROM couriers c, pickup p
+  WHERE c.available AND c.location IS NOT NULL
+    AND NOT EXISTS (
+      SELECT 1 FROM orders o
+      WHERE o.courier_id = c.user_id AND o.status NOT IN ('delivered', 'cancelled', 'rejected')
+    )
+  ORDER BY c.location <-> p.location
+  LIMIT 1
+  FOR UPDATE OF c SKIP LOCKED
+)
+UPDATE orders
+SET courier_id = courier.user_id
+FROM courier
+WHERE orders.id = sqlc.arg(order_id) AND orders.courier_id IS NULL;
+
+-- name: AssignNearestOrder :exec
+-- Assigns the courier, when available and without an active order, the
+-- waiting order whose first stop is closest
+WITH courier AS (
+  SELECT c.user_id, c.location
+  FROM couriers c
+  WHERE c.user_id = sqlc.arg(courier_id) AND c.available AND c.location IS NOT NULL
+    AND NOT EXISTS (
+      SELECT 1 FROM orders o
+      WHERE o.courier_id = c.user_id AND o.status NOT IN ('delivered', 'cancelled', 'rejected')
+    )
+  FOR UPDATE OF c
+), next_order AS (
+  SELECT o.id
+  FROM orders o
+  JOIN order_merchants om ON om.order_id = o.id AND om.position = 0
+  CROSS JOIN courier
+  WHERE o.courier_id IS NULL AND o.status IN ('placed', 'accepted', 'preparing')
+  ORDER BY om.location <-> courier.location
+  LIMIT 1
+  FOR UPDATE OF o SKIP LOCKED
+)
+UPDATE orders
+SET courier_id = courier.user_id
+FROM next_order, courier
+WHERE orders.id = next_order.id;
+
+-- name: GetOrderCourierID :one
+SELECT courier_id FROM orders WHERE id = $1;
+
+-- name: GetCourierOrderForUpdate :one
+SELECT status, courier_accepted_at
+FROM orders
+WHERE id = $1 AND courier_id = $2
+FOR UPDATE;
+
+-- name: AcceptCourierOrder :exec
+UPDATE orders SET courier_accepted_at = CURRENT_TIMESTAMP WHERE id = $1;
+
+-- name: GetCourierOrders :many
+-- Orders assigned to the courier that are not finished yet, oldest first
+SELECT
+  o.id,
+  o.status,
+  o.courier_accepted_at,
+  o.created_at,
+  (ce.estimate_data->'userLocation'->>'lat')::float8 AS user_lat,
+  (ce.estimate_data->'userLocation'->>'long')::float8 AS user_long
+FROM orders o
+JOIN calculated_estimates ce ON ce.id = o.calculated_estimate_id
+WHERE o.courier_id = $1 AND o.status NOT IN ('delivered', 'cancelled', 'rejected')
+ORDER BY o.created_at, o.id;
+
+-- name: GetOrderPickups :many
+SELECT
+  om.order_id,
+  om.merchant_id,
+  om.position,
+  om.name,
+  ST_Y(om.location)::float8 AS lat,
+  ST_X(om.location)::float8 AS long,
+  om.status,
+  om.picked_up_at
+FROM order_merchants om
+WHERE om.order_id = ANY(sqlc.arg(order_ids)::uuid[])
+ORDER BY om.order_id, om.position;
+
+-- name: MarkOrderMerchantPickedUp :exec
+UPDATE order_merchants
+SET picked_up_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE order_id = $1 AND merchant_id = $2;
@@ -1,14 +1,36 @@
--- name: GetMerchantLocationByID :one
-SELECT 
-  id::text AS id,
+-- name: GetMerchantCategorySettings :many
+SELECT
+  merchant_category,
+  prep_time_minutes,
+  base_delivery_fee,
+  per_km_fee,
+  small_order_threshold,
+  small_order_surcharge,
+  service_fee_percent
+FROM merchant_category_settings;
+
+
+-- name: GetEstimateMerchants :many
+SELECT
+  id,
+  merchant_category,
   ST_Y(location::geometry)::float8 AS lat,
-  ST_X(location::geometry)::float8 AS long
+  ST_X(location::geometry)::float8 AS long,
+  time_zone,
+  merchant_can_deliver(
+    location,
+    delivery_radius_m,
+    ST_SetSRID(ST_MakePoint(sqlc.arg(user_long)::float8, sqlc.arg(user_lat)::float8), 4326)
+  )::bool AS deliverable
 FROM merchants
-WHERE id = ($1)::text::uuid;
+WHERE deleted_at IS NULL AND id = ANY(sqlc.arg(ids)::uuid[]);
 
--- name: GetMerchantItemPriceByID :one
-SELECT 
-  id::text AS id,
-  price::int4 AS price
+-- name: GetEstimateItems :many
+SELECT
+  id,
+  merchant_id,
+  price,
+  available,
+  stock
 FROM merchant_items
-WHERE id = ($1)::text::uuid;
\ No newline at end of file
+WHERE deleted_at IS NULL AND id = ANY(sqlc.arg(ids)::uuid[]);
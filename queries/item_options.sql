@@ -0,0 +1,77 @@
+-- name: GetItemOptionGroups :many
+SELECT * FROM item_option_groups
+WHERE item_id = ANY(sqlc.arg(item_ids)::uuid[])
+ORDER BY item_id, position;
+
+-- name: GetItemOptions :many
+SELECT
+  io.id,
+  io.group_id,
+  g.item_id,
+  io.name,
+  io.price_delta
+FROM item_options io
+JOIN item_option_groups g ON g.id = io.group_id
+WHERE g.item_id = ANY(sqlc.arg(item_ids)::uuid[])
+ORDER BY g.item_id, g.position, io.position;
+
+-- name: GetMerchantItemForUpdate :one
+SELECT id FROM merchant_items
+WHERE id = $1 AND merchant_id = $2 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: DeleteItemOptionGroups :exec
+DELETE FROM item_option_groups WHERE item_id = $1;
+
+-- name: CreateItemOptionGroup :one
+INSERT INTO item_option_groups (
+  item_id, name, min_selections, max_selections, position
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id;
+
+-- name: CreateItemOptions :exec
+INSERT INTO item_options (group_id, name, price_delta, position)
+SELECT sqlc.arg(group_id)::uuid, unnest(sqlc.arg(names)::text[]), unnest(sqlc.arg(price_deltas)::int[]), unnest(sqlc.arg(positions)::int[]);
+
+-- name: CreateOrderItemOptions :exec
+-- Group and option names and the price delta are copied at order time
+INSERT INTO order_item_options (
+  order_id, merchant_id, item_position, position,
+  option_id, group_name, name, price_delta
+)
+SELECT sqlc.arg(order_id)::uuid, o.merchant_id, o.item_position, o.position,
+  io.id, g.name, io.name, io.price_delta
+FROM unnest(
+  sqlc.arg(merchant_ids)::uuid[],
+  sqlc.arg(item_positions)::int[],
+  sqlc.arg(positions)::int[],
+  sqlc.arg(option_ids)::uuid[]
+) AS o(merchant_id, item_position, position, option_id)
+JOIN item_options io ON io.id = o.option_id
+JOIN item_option_groups g ON g.id = io.group_id;
+
+-- name: GetMerchantOrderItemOptions :many
+SELECT
+  order_id,
+  item_position,
+  option_id,
+  group_name,
+  name,
+  price_delta
+FROM order_item_options
+WHERE order_id = ANY(sqlc.arg(order_ids)::uuid[]) AND merchant_id = sqlc.arg(merchant_id)
+ORDER BY order_id, item_position, position;
+
+-- name: GetOrderHistoryItemOptions :many
+SELECT
+  order_id,
+  merchant_id,
+  item_position,
+  option_id,
+  group_name,
+  name,
+  price_delta
+FROM order_item_options
+WHERE order_id = ANY(sqlc.arg(order_ids)::uuid[])
+ORDER BY order_id, merchant_id, item_position, position;
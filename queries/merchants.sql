@@ -1,8 +1,8 @@
 -- name: CreateMerchant :one
 INSERT INTO merchants (
-  name, merchant_category, image_url, location
+  name, merchant_category, image_url, location, delivery_radius_m
 ) VALUES (
-  $1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326)
+  $1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326), $6
 ) RETURNING id;
 
 -- name: GetMerchants :many
@@ -13,7 +13,11 @@ SELECT
   COALESCE(m.image_url, '') as image_url,
   ST_Y(m.location::geometry) as lat,
   ST_X(m.location::geometry) as long,
-  m.created_at
+  m.created_at,
+  m.delivery_radius_m,
+  m.rating_count,
+  COALESCE(ROUND(m.rating_sum::numeric / NULLIF(m.rating_count, 0), 2), 0)::float8 AS rating_average,
+  m.deleted_at
 FROM merchants m
 WHERE
   (sqlc.narg(merchant_id)::text IS NULL OR m.id::text = sqlc.narg(merchant_id))
@@ -22,7 +26,14 @@ WHERE
     sqlc.narg(name)::text IS NULL
     OR LOWER(m.name) LIKE LOWER('%' || sqlc.narg(name) || '%')
   )
+  AND (
+    sqlc.narg(min_rating)::float8 IS NULL
+    OR (m.rating_count > 0 AND m.rating_sum::float8 / m.rating_count >= sqlc.narg(min_rating))
+  )
+  AND (m.deleted_at IS NOT NULL) = sqlc.arg(deleted)::bool
 ORDER BY
+  CASE WHEN sqlc.arg(rating) = 'asc' THEN m.rating_sum::float8 / NULLIF(m.rating_count, 0) END ASC NULLS LAST,
+  CASE WHEN sqlc.arg(rating) = 'desc' THEN m.rating_sum::float8 / NULLIF(m.rating_count, 0) END DESC NULLS LAST,
   CASE WHEN sqlc.arg(created_at) = 'asc' THEN m.created_at END ASC,
   CASE WHEN sqlc.arg(created_at) = 'desc' THEN m.created_at END DESC,
   m.id ASC
@@ -37,7 +48,12 @@ WHERE
   AND (
     sqlc.narg(name)::text IS NULL
     OR LOWER(m.name) LIKE LOWER('%' || sqlc.narg(name) || '%')
-  );
+  )
+  AND (
+    sqlc.narg(min_rating)::float8 IS NULL
+    OR (m.rating_count > 0 AND m.rating_sum::float8 / m.rating_count >= sqlc.narg(min_rating))
+  )
+  AND (m.deleted_at IS NOT NULL) = sqlc.arg(deleted)::bool;
 
 -- name: CreateMerchantItem :one
 INSERT INTO merchant_items (
@@ -49,6 +65,9 @@ INSERT INTO merchant_items (
 -- name: GetMerchantByID :one
 SELECT EXISTS(SELECT 1 FROM merchants WHERE id = $1);
 
+-- name: GetActiveMerchantByID :one
+SELECT EXISTS(SELECT 1 FROM merchants WHERE id = $1 AND deleted_at IS NULL);
+
 -- name: GetMerchantItems :many
 SELECT
   mi.id,
@@ -56,7 +75,10 @@ SELECT
   mi.product_category,
   mi.price,
   COALESCE(mi.image_url, '') as image_url,
-  mi.created_at
+  mi.created_at,
+  mi.available,
+  mi.stock,
+  mi.deleted_at
 FROM merchant_items mi
 WHERE mi.merchant_id = sqlc.arg(merchant_id)
   AND (sqlc.narg(item_id)::text IS NULL OR mi.id::text = sqlc.narg(item_id))
@@ -65,6 +87,7 @@ WHERE mi.merchant_id = sqlc.arg(merchant_id)
     sqlc.narg(name)::text IS NULL
     OR LOWER(mi.name) LIKE LOWER('%' || sqlc.narg(name) || '%')
   )
+  AND (mi.deleted_at IS NOT NULL) = sqlc.arg(deleted)::bool
 ORDER BY
   CASE WHEN sqlc.arg(created_at) = 'asc' THEN mi.created_at END ASC,
   CASE WHEN sqlc.arg(created_at) = 'desc' THEN mi.created_at END DESC,
@@ -80,27 +103,64 @@ WHERE mi.merchant_id = sqlc.arg(merchant_id)
   AND (
     sqlc.narg(name)::text IS NULL
     OR LOWER(mi.name) LIKE LOWER('%' || sqlc.narg(name) || '%')
-  );
+  )
+  AND (mi.deleted_at IS NOT NULL) = sqlc.arg(deleted)::bool;
 
--- name: GetMerchantDetailsByID :one
-SELECT
-  m.id,
-  m.name,
-  m.merchant_category,
-  COALESCE(m.image_url, '') as image_url,
-  ST_Y(m.location::geometry) as lat,
-  ST_X(m.location::geometry) as long,
-  m.created_at
-FROM merchants m
-WHERE m.id = sqlc.arg(id)::uuid;
+-- name: UpdateMerchant :one
+-- Fields left null keep their value, the location only moves when both
+-- coordinates are given
+UPDATE merchants
+SET
+  name = COALESCE(sqlc.narg(name)::text, name),
+  merchant_category = COALESCE(sqlc.narg(merchant_category)::merchant_category, merchant_category),
+  image_url = COALESCE(sqlc.narg(image_url)::text, image_url),
+  location = CASE
+    WHEN sqlc.narg(lat)::float8 IS NULL OR sqlc.narg(long)::float8 IS NULL THEN location
+    ELSE ST_SetSRID(ST_MakePoint(sqlc.narg(long)::float8, sqlc.narg(lat)::float8), 4326)
+  END,
+  delivery_radius_m = COALESCE(sqlc.narg(delivery_radius_m)::int, delivery_radius_m)
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
+RETURNING
+  id,
+  name,
+  merchant_category,
+  COALESCE(image_url, '') AS image_url,
+  ST_Y(location::geometry)::float8 AS lat,
+  ST_X(location::geometry)::float8 AS long,
+  created_at,
+  delivery_radius_m;
 
--- name: GetMerchantItemByID :one
-SELECT
-  mi.id,
-  mi.name,
-  mi.product_category,
-  mi.price,
-  COALESCE(mi.image_url, '') as image_url,
-  mi.created_at
-FROM merchant_items mi
-WHERE mi.id = sqlc.arg(id)::uuid;
+-- name: DeleteMerchant :execrows
+UPDATE merchants SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreMerchant :execrows
+UPDATE merchants SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: UpdateMerchantItem :one
+-- Fields left null keep their value
+UPDATE merchant_items
+SET
+  name = COALESCE(sqlc.narg(name)::text, name),
+  product_category = COALESCE(sqlc.narg(product_category)::product_category, product_category),
+  price = COALESCE(sqlc.narg(price)::int, price),
+  image_url = COALESCE(sqlc.narg(image_url)::text, image_url)
+WHERE id = sqlc.arg(id) AND merchant_id = sqlc.arg(merchant_id) AND deleted_at IS NULL
+RETURNING
+  id,
+  name,
+  product_category,
+  price,
+  COALESCE(image_url, '') AS image_url,
+  created_at,
+  available,
+  stock;
+
+-- name: DeleteMerchantItem :execrows
+UPDATE merchant_items SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND merchant_id = $2 AND deleted_at IS NULL;
+
+-- name: RestoreMerchantItem :execrows
+UPDATE merchant_items SET deleted_at = NULL
+WHERE id = $1 AND merchant_id = $2 AND deleted_at IS NOT NULL;
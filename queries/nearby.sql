@@ -7,10 +7,15 @@ SELECT
   ST_Y(m.location::geometry) AS lat,
   ST_X(m.location::geometry) AS long,
   m.created_at,
+  m.time_zone,
+  m.rating_count,
+  COALESCE(ROUND(m.rating_sum::numeric / NULLIF(m.rating_count, 0), 2), 0)::float8 AS rating_average,
   ST_DistanceSphere(m.location, ST_SetSRID(ST_MakePoint(sqlc.arg(long), sqlc.arg(lat)), 4326)) AS distance
 FROM merchants m
 WHERE
-  (sqlc.narg(merchant_id)::text IS NULL OR m.id::text = sqlc.narg(merchant_id))
+  m.deleted_at IS NULL
+  AND merchant_can_deliver(m.location, m.delivery_radius_m, ST_SetSRID(ST_MakePoint(sqlc.arg(long), sqlc.arg(lat)), 4326))
+  AND (sqlc.narg(merchant_id)::text IS NULL OR m.id::text = sqlc.narg(merchant_id))
   AND (sqlc.narg(merchant_category)::text IS NULL OR m.merchant_category::text = sqlc.narg(merchant_category))
   AND (
     sqlc.narg(name)::text IS NULL
@@ -18,17 +23,28 @@ WHERE
     OR EXISTS (
       SELECT 1 FROM merchant_items mi
       WHERE mi.merchant_id = m.id
+        AND mi.deleted_at IS NULL
         AND LOWER(mi.name) LIKE LOWER('%' || sqlc.narg(name) || '%')
     )
   )
-ORDER BY distance ASC, m.id ASC
+  AND (
+    sqlc.narg(min_rating)::float8 IS NULL
+    OR (m.rating_count > 0 AND m.rating_sum::float8 / m.rating_count >= sqlc.narg(min_rating))
+  )
+ORDER BY
+  CASE WHEN sqlc.arg(rating) = 'asc' THEN m.rating_sum::float8 / NULLIF(m.rating_count, 0) END ASC NULLS LAST,
+  CASE WHEN sqlc.arg(rating) = 'desc' THEN m.rating_sum::float8 / NULLIF(m.rating_count, 0) END DESC NULLS LAST,
+  distance ASC,
+  m.id ASC
 LIMIT sqlc.arg(row_limit)::int OFFSET sqlc.arg(row_offset)::int;
 
 -- name: CountNearbyMerchants :one
 SELECT COUNT(*)
 FROM merchants m
 WHERE
-  (sqlc.narg(merchant_id)::text IS NULL OR m.id::text = sqlc.narg(merchant_id))
+  m.deleted_at IS NULL
+  AND merchant_can_deliver(m.location, m.delivery_radius_m, ST_SetSRID(ST_MakePoint(sqlc.arg(long), sqlc.arg(lat)), 4326))
+  AND (sqlc.narg(merchant_id)::text IS NULL OR m.id::text = sqlc.narg(merchant_id))
   AND (sqlc.narg(merchant_category)::text IS NULL OR m.merchant_category::text = sqlc.narg(merchant_category))
   AND (
     sqlc.narg(name)::text IS NULL
@@ -36,8 +52,13 @@ WHERE
     OR EXISTS (
       SELECT 1 FROM merchant_items mi
       WHERE mi.merchant_id = m.id
+        AND mi.deleted_at IS NULL
         AND LOWER(mi.name) LIKE LOWER('%' || sqlc.narg(name) || '%')
     )
+  )
+  AND (
+    sqlc.narg(min_rating)::float8 IS NULL
+    OR (m.rating_count > 0 AND m.rating_sum::float8 / m.rating_count >= sqlc.narg(min_rating))
   );
 
 
@@ -0,0 +1,39 @@
+-- name: GetMerchantTimeZone :one
+SELECT time_zone FROM merchants WHERE id = $1;
+
+-- name: UpdateMerchantTimeZone :exec
+UPDATE merchants SET time_zone = $2 WHERE id = $1;
+
+-- name: DeleteMerchantOpeningHours :exec
+DELETE FROM merchant_opening_hours WHERE merchant_id = $1;
+
+-- name: CreateMerchantOpeningHours :exec
+INSERT INTO merchant_opening_hours (
+  merchant_id, day_of_week, opens_at, closes_at
+) VALUES (
+  $1, $2, $3, $4
+);
+
+-- name: GetMerchantOpeningHours :many
+SELECT merchant_id, day_of_week, opens_at, closes_at
+FROM merchant_opening_hours
+WHERE merchant_id = ANY(sqlc.arg(merchant_ids)::uuid[])
+ORDER BY merchant_id, day_of_week, opens_at;
+
+-- name: UpsertMerchantHoliday :exec
+INSERT INTO merchant_holidays (
+  merchant_id, holiday_date, note
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (merchant_id, holiday_date) DO UPDATE SET note = EXCLUDED.note;
+
+-- name: DeleteMerchantHoliday :execrows
+DELETE FROM merchant_holidays WHERE merchant_id = $1 AND holiday_date = $2;
+
+-- name: GetMerchantHolidays :many
+SELECT merchant_id, holiday_date, note
+FROM merchant_holidays
+WHERE merchant_id = ANY(sqlc.arg(merchant_ids)::uuid[])
+  AND holiday_date >= sqlc.arg(since)::date
+ORDER BY merchant_id, holiday_date;
@@ -0,0 +1,15 @@
+-- name: GetUserOrderForCancellation :one
+SELECT
+  o.status,
+  ce.total_price
+FROM orders o
+JOIN calculated_estimates ce ON ce.id = o.calculated_estimate_id
+WHERE o.id = sqlc.arg(id) AND o.user_id = sqlc.arg(user_id)
+FOR UPDATE OF o;
+
+-- name: CreateOrderCancellation :exec
+INSERT INTO order_cancellations (
+  order_id, reason, fee, refund_amount, cancelled_by
+) VALUES (
+  $1, $2, $3, $4, $5
+);
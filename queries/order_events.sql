@@ -0,0 +1,12 @@
+-- name: CreateOrderEvent :exec
+INSERT INTO order_events (order_id, type, data)
+VALUES ($1, $2, $3);
+
+-- name: GetOrderEventsSince :many
+SELECT id, order_id, type, data, created_at
+FROM order_events
+WHERE order_id = $1 AND id > $2
+ORDER BY id;
+
+-- name: GetOrderUserID :one
+SELECT user_id FROM orders WHERE id = $1;
@@ -0,0 +1,80 @@
+-- name: CreateOrderMerchants :exec
+-- Details are copied from the merchant at order time
+INSERT INTO order_merchants (
+  order_id, merchant_id, position, subtotal,
+  name, merchant_category, image_url, location, merchant_created_at
+)
+SELECT sqlc.arg(order_id)::uuid, p.merchant_id, p.position - 1, p.subtotal,
+  m.name, m.merchant_category, m.image_url, m.location, m.created_at
+FROM unnest(sqlc.arg(merchant_ids)::uuid[], sqlc.arg(subtotals)::int[]) WITH ORDINALITY AS p(merchant_id, subtotal, position)
+JOIN merchants m ON m.id = p.merchant_id;
+
+-- name: CreateOrderItems :exec
+-- Details and the unit price are copied from the item at order time
+INSERT INTO order_items (
+  order_id, merchant_id, item_id, quantity, position,
+  name, product_category, unit_price, image_url, item_created_at
+)
+SELECT sqlc.arg(order_id)::uuid, i.merchant_id, i.item_id, i.quantity, i.position,
+  mi.name, mi.product_category, mi.price, mi.image_url, mi.created_at
+FROM unnest(
+  sqlc.arg(merchant_ids)::uuid[],
+  sqlc.arg(item_ids)::uuid[],
+  sqlc.arg(quantities)::int[],
+  sqlc.arg(positions)::int[]
+) AS i(merchant_id, item_id, quantity, position)
+JOIN merchant_items mi ON mi.id = i.item_id;
+
+-- name: GetOrderMerchantStatus :one
+SELECT status
+FROM order_merchants
+WHERE order_id = $1 AND merchant_id = $2
+FOR UPDATE;
+
+-- name: GetOrderMerchantStatuses :many
+SELECT status FROM order_merchants WHERE order_id = $1;
+
+-- name: UpdateOrderMerchantStatus :exec
+UPDATE order_merchants
+SET status = $3, updated_at = CURRENT_TIMESTAMP
+WHERE order_id = $1 AND merchant_id = $2;
+
+-- name: GetMerchantOrderItems :many
+-- The merchant's items of a page of orders, in the order they were requested
+SELECT
+  oi.order_id,
+  oi.item_id,
+  oi.position,
+  oi.name,
+  oi.quantity
+FROM order_items oi
+WHERE oi.order_id = ANY(sqlc.arg(order_ids)::uuid[]) AND oi.merchant_id = sqlc.arg(merchant_id)
+ORDER BY oi.order_id, oi.position;
+
+-- name: GetMerchantOrders :many
+SELECT
+  o.id,
+  o.status AS order_status,
+  om.status,
+  om.position,
+  om.subtotal,
+  o.created_at
+FROM order_merchants om
+JOIN orders o ON o.id = om.order_id
+WHERE om.merchant_id = sqlc.arg(merchant_id)
+  AND o.status <> 'pending_payment'
+  AND (sqlc.narg(status)::text IS NULL OR om.status::text = sqlc.narg(status) OR o.status::text = sqlc.narg(status))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR o.created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR o.created_at < sqlc.narg(created_to))
+ORDER BY o.created_at DESC, o.id ASC
+LIMIT sqlc.arg(limit_val)::int OFFSET sqlc.arg(offset_val)::int;
+
+-- name: CountMerchantOrders :one
+SELECT COUNT(*)
+FROM order_merchants om
+JOIN orders o ON o.id = om.order_id
+WHERE om.merchant_id = sqlc.arg(merchant_id)
+  AND o.status <> 'pending_payment'
+  AND (sqlc.narg(status)::text IS NULL OR om.status::text = sqlc.narg(status) OR o.status::text = sqlc.narg(status))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR o.created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR o.created_at < sqlc.narg(created_to));
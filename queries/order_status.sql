@@ -0,0 +1,12 @@
+-- name: GetOrderStatusForUpdate :one
+SELECT status FROM orders WHERE id = $1 FOR UPDATE;
+
+-- name: UpdateOrderStatus :exec
+UPDATE orders SET status = $2 WHERE id = $1;
+
+-- name: CreateOrderStatusHistory :exec
+INSERT INTO order_status_history (
+  order_id, from_status, to_status, actor_id, actor_role
+) VALUES (
+  $1, $2, $3, $4, $5
+);
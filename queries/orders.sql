@@ -1,8 +1,8 @@
 -- name: CreateCalculatedEstimate :one
 INSERT INTO calculated_estimates (
-  user_id, total_price, estimated_delivery_time_minutes, estimate_data
+  user_id, total_price, estimated_delivery_time_minutes, estimate_data, expires_at
 ) VALUES (
-  sqlc.arg(user_id)::uuid, sqlc.arg(total_price), sqlc.arg(estimated_delivery_time_minutes), sqlc.arg(estimate_data)
+  sqlc.arg(user_id)::uuid, sqlc.arg(total_price), sqlc.arg(estimated_delivery_time_minutes), sqlc.arg(estimate_data), sqlc.arg(expires_at)
 ) RETURNING id;
 
 -- name: GetCalculatedEstimateByID :one
@@ -12,29 +12,140 @@ SELECT
   total_price,
   estimated_delivery_time_minutes,
   estimate_data,
-  created_at
+  created_at,
+  expires_at
 FROM calculated_estimates
 WHERE id = sqlc.arg(id)::uuid;
 
 -- name: CreateOrder :one
 INSERT INTO orders (
-  user_id, calculated_estimate_id
+  user_id, calculated_estimate_id, status
 ) VALUES (
-  sqlc.arg(user_id)::uuid, sqlc.arg(calculated_estimate_id)::uuid
+  sqlc.arg(user_id)::uuid, sqlc.arg(calculated_estimate_id)::uuid, sqlc.arg(status)
 ) RETURNING id;
 
--- name: GetOrdersByUserID :many
-SELECT 
+-- name: GetUserOrderEstimate :one
+SELECT ce.estimate_data
+FROM orders o
+JOIN calculated_estimates ce ON ce.id = o.calculated_estimate_id
+WHERE o.id = $1 AND o.user_id = $2;
+
+-- name: GetUserOrders :many
+-- Filters match when any merchant part of the order does. name matches
+-- merchant and item names.
+SELECT
   o.id,
+  o.status,
   o.created_at,
   ce.estimate_data
 FROM orders o
-JOIN calculated_estimates ce ON o.calculated_estimate_id = ce.id
-WHERE o.user_id = $1::uuid
-ORDER BY o.created_at DESC
-LIMIT $2 OFFSET $3;
+JOIN calculated_estimates ce ON ce.id = o.calculated_estimate_id
+WHERE o.user_id = sqlc.arg(user_id)
+  AND (
+    sqlc.narg(merchant_id)::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND om.merchant_id::text = sqlc.narg(merchant_id)
+    )
+  )
+  AND (
+    sqlc.narg(merchant_category)::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND om.merchant_category::text = sqlc.narg(merchant_category)
+    )
+  )
+  AND (
+    sqlc.narg(name)::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND LOWER(om.name) LIKE LOWER('%' || sqlc.narg(name) || '%')
+    )
+    OR EXISTS (
+      SELECT 1 FROM order_items oi
+      WHERE oi.order_id = o.id AND LOWER(oi.name) LIKE LOWER('%' || sqlc.narg(name) || '%')
+    )
+  )
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR o.created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR o.created_at < sqlc.narg(created_to))
+ORDER BY
+  CASE WHEN sqlc.arg(created_at) = 'asc' THEN o.created_at END ASC,
+  CASE WHEN sqlc.arg(created_at) = 'desc' THEN o.created_at END DESC,
+  o.id ASC
+LIMIT sqlc.arg(limit_val)::int OFFSET sqlc.arg(offset_val)::int;
 
--- name: GetOrdersCountByUserID :one
+-- name: CountUserOrders :one
 SELECT COUNT(*)
 FROM orders o
-WHERE o.user_id = $1::uuid;
\ No newline at end of file
+WHERE o.user_id = sqlc.arg(user_id)
+  AND (
+    sqlc.narg(merchant_id)::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND om.merchant_id::text = sqlc.narg(merchant_id)
+    )
+  )
+  AND (
+    sqlc.narg(merchant_category)::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND om.merchant_category::text = sqlc.narg(merchant_category)
+    )
+  )
+  AND (
+    sqlc.narg(name)::text IS NULL
+    OR EXISTS (
+      SELECT 1 FROM order_merchants om
+      WHERE om.order_id = o.id AND LOWER(om.name) LIKE LOWER('%' || sqlc.narg(name) || '%')
+    )
+    OR EXISTS (
+      SELECT 1 FROM order_items oi
+      WHERE oi.order_id = o.id AND LOWER(oi.name) LIKE LOWER('%' || sqlc.narg(name) || '%')
+    )
+  )
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR o.created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR o.created_at < sqlc.narg(created_to));
+
+-- name: GetOrderHistoryMerchants :many
+SELECT
+  om.order_id,
+  om.merchant_id,
+  om.name,
+  om.merchant_category,
+  om.image_url,
+  ST_Y(om.location)::float8 AS lat,
+  ST_X(om.location)::float8 AS long,
+  om.merchant_created_at
+FROM order_merchants om
+WHERE om.order_id = ANY(sqlc.arg(order_ids)::uuid[])
+ORDER BY om.order_id, om.position;
+
+-- name: GetOrderHistoryItems :many
+SELECT
+  oi.order_id,
+  oi.merchant_id,
+  oi.item_id,
+  oi.position,
+  oi.name,
+  oi.product_category,
+  oi.unit_price,
+  oi.quantity,
+  oi.image_url,
+  oi.item_created_at
+FROM order_items oi
+WHERE oi.order_id = ANY(sqlc.arg(order_ids)::uuid[])
+ORDER BY oi.order_id, oi.merchant_id, oi.position;
+
+-- name: GetOrderIdempotencyKey :one
+SELECT
+  request_hash,
+  order_id
+FROM order_idempotency_keys
+WHERE user_id = sqlc.arg(user_id)::uuid AND idempotency_key = sqlc.arg(idempotency_key);
+
+-- name: CreateOrderIdempotencyKey :exec
+INSERT INTO order_idempotency_keys (
+  user_id, idempotency_key, request_hash, order_id
+) VALUES (
+  sqlc.arg(user_id)::uuid, sqlc.arg(idempotency_key), sqlc.arg(request_hash), sqlc.arg(order_id)::uuid
+);
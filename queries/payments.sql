@@ -0,0 +1,63 @@
+-- name: CreatePayment :one
+INSERT INTO payments (
+  order_id, provider, method, amount
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetOrderPayment :one
+SELECT * FROM payments WHERE order_id = $1;
+
+-- name: GetExpiredPaymentOrders :many
+-- Orders still waiting for their payment since before the cutoff, oldest
+-- first
+SELECT id FROM orders
+WHERE status = 'pending_payment' AND created_at < sqlc.arg(cutoff)
+ORDER BY created_at
+LIMIT sqlc.arg(max_orders);
+
+-- name: GetPaymentByIntent :one
+SELECT * FROM payments
+WHERE provider = $1 AND intent_id = $2;
+
+-- name: GetPaymentByIntentForUpdate :one
+SELECT * FROM payments
+WHERE provider = $1 AND intent_id = $2
+FOR UPDATE;
+
+-- name: GetPendingRefundForUpdate :one
+SELECT * FROM payments
+WHERE order_id = $1 AND status = 'refund_pending'
+FOR UPDATE;
+
+-- name: SetPaymentIntent :execrows
+-- Only a pending payment without an intent takes one, a concurrent retry may
+-- have stored its own first
+UPDATE payments
+SET intent_id = $2, client_secret = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND intent_id IS NULL AND status = 'pending';
+
+-- name: UpdatePaymentStatus :exec
+UPDATE payments
+SET status = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: RequestOrderPaymentRefund :exec
+-- Captured payments of a cancelled or rejected order are owed back, less any
+-- cancellation fee. Payments not captured yet are cancelled.
+UPDATE payments
+SET
+  status = CASE WHEN status = 'captured' THEN 'refund_pending' ELSE 'cancelled' END::payment_status,
+  refund_amount = CASE WHEN status = 'captured' THEN COALESCE(
+    (SELECT oc.refund_amount FROM order_cancellations oc WHERE oc.order_id = payments.order_id),
+    amount
+  ) END,
+  updated_at = CURRENT_TIMESTAMP
+WHERE order_id = $1 AND status IN ('pending', 'authorized', 'captured');
+
+-- name: CreatePaymentWebhookEvent :execrows
+-- No row is inserted for an event that was already handled
+INSERT INTO payment_webhook_events (provider, event_id, type)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
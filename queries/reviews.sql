@@ -0,0 +1,31 @@
+-- name: GetUserOrderStatus :one
+SELECT status FROM orders WHERE id = $1 AND user_id = $2;
+
+-- name: CreateMerchantReview :exec
+INSERT INTO merchant_reviews (
+  order_id, merchant_id, user_id, rating, comment, image_url
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+);
+
+-- name: AddMerchantRating :exec
+UPDATE merchants
+SET rating_sum = rating_sum + sqlc.arg(rating)::int, rating_count = rating_count + 1
+WHERE id = sqlc.arg(id);
+
+-- name: GetMerchantReviews :many
+SELECT
+  r.order_id,
+  r.rating,
+  r.comment,
+  r.image_url,
+  r.created_at,
+  u.username
+FROM merchant_reviews r
+JOIN users u ON u.id = r.user_id
+WHERE r.merchant_id = sqlc.arg(merchant_id)
+ORDER BY r.created_at DESC, r.order_id ASC
+LIMIT sqlc.arg(limit_val)::int OFFSET sqlc.arg(offset_val)::int;
+
+-- name: CountMerchantReviews :one
+SELECT COUNT(*) FROM merchant_reviews WHERE merchant_id = $1;
@@ -0,0 +1,32 @@
+-- name: UpdateMerchantItemAvailability :execrows
+UPDATE merchant_items
+SET available = sqlc.arg(available), stock = sqlc.narg(stock)
+WHERE id = sqlc.arg(id) AND merchant_id = sqlc.arg(merchant_id) AND deleted_at IS NULL;
+
+-- name: ReserveItemStock :many
+-- Takes stock for every item that is available and has enough of it. Items
+-- missing from the result could not be reserved.
+UPDATE merchant_items mi
+SET stock = mi.stock - r.quantity
+FROM unnest(sqlc.arg(item_ids)::uuid[], sqlc.arg(quantities)::int[]) AS r(item_id, quantity)
+WHERE mi.id = r.item_id
+  AND mi.available
+  AND mi.deleted_at IS NULL
+  AND (mi.stock IS NULL OR mi.stock >= r.quantity)
+RETURNING mi.id, (mi.stock IS NOT NULL)::bool AS tracked;
+
+-- name: CreateOrderReservations :exec
+INSERT INTO order_item_reservations (order_id, item_id, quantity)
+SELECT sqlc.arg(order_id)::uuid, unnest(sqlc.arg(item_ids)::uuid[]), unnest(sqlc.arg(quantities)::int[]);
+
+-- name: ReleaseOrderReservations :exec
+WITH released AS (
+  UPDATE order_item_reservations
+  SET released_at = CURRENT_TIMESTAMP
+  WHERE order_id = sqlc.arg(order_id) AND released_at IS NULL
+  RETURNING item_id, quantity
+)
+UPDATE merchant_items mi
+SET stock = mi.stock + r.quantity
+FROM released r
+WHERE mi.id = r.item_id AND mi.stock IS NOT NULL;
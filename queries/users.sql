@@ -15,5 +15,8 @@ INSERT INTO users (
 -- name: GetAdminByUsername :one
 SELECT * FROM users where username = $1 AND role = 'admin';
 
+-- name: GetCourierByUsername :one
+SELECT * FROM users where username = $1 AND role = 'courier';
+
 -- name: GetUserByUsername :one
 SELECT * FROM users where username = $1 AND role = 'user';
\ No newline at end of file
@@ -0,0 +1,69 @@
+-- name: LockUserWallet :one
+-- Creates the wallet on first use. The wallet row stays locked until the
+-- transaction ends, so balance checks of concurrent debits are serialized.
+INSERT INTO ledger_accounts (type, user_id)
+VALUES ('wallet', $1)
+ON CONFLICT (user_id) DO UPDATE SET user_id = EXCLUDED.user_id
+RETURNING id;
+
+-- name: GetLedgerAccountID :one
+SELECT id FROM ledger_accounts
+WHERE type = sqlc.arg(account_type)::ledger_account_type AND user_id IS NULL;
+
+-- name: GetAccountBalance :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS balance
+FROM ledger_entries
+WHERE account_id = $1;
+
+-- name: GetUserBalance :one
+SELECT COALESCE(SUM(e.amount), 0)::bigint AS balance
+FROM ledger_accounts a
+JOIN ledger_entries e ON e.account_id = a.id
+WHERE a.user_id = $1;
+
+-- name: CreateLedgerTransfer :one
+-- Moves amount between two accounts as a balanced pair of entries
+WITH transfer AS (
+  INSERT INTO ledger_transactions (kind, order_id, created_by)
+  VALUES (sqlc.arg(kind), sqlc.narg(order_id), sqlc.narg(created_by))
+  RETURNING id
+), entries AS (
+  INSERT INTO ledger_entries (transaction_id, account_id, amount)
+  SELECT transfer.id, e.account_id, e.amount
+  FROM transfer, (VALUES
+    (sqlc.arg(from_account)::uuid, -sqlc.arg(amount)::bigint),
+    (sqlc.arg(to_account)::uuid, sqlc.arg(amount)::bigint)
+  ) AS e(account_id, amount)
+)
+SELECT id FROM transfer;
+
+-- name: GetOrderRefund :one
+-- The payment goes back in full unless the user cancelled, then only the
+-- cancellation's refund_amount does
+SELECT
+  e.account_id AS wallet_id,
+  COALESCE(oc.refund_amount, -e.amount)::bigint AS amount
+FROM ledger_transactions t
+JOIN ledger_entries e ON e.transaction_id = t.id AND e.amount < 0
+LEFT JOIN order_cancellations oc ON oc.order_id = t.order_id
+WHERE t.order_id = $1 AND t.kind = 'order_payment';
+
+-- name: GetUserWalletEntries :many
+SELECT
+  t.id,
+  t.kind,
+  t.order_id,
+  e.amount,
+  t.created_at
+FROM ledger_accounts a
+JOIN ledger_entries e ON e.account_id = a.id
+JOIN ledger_transactions t ON t.id = e.transaction_id
+WHERE a.user_id = sqlc.arg(user_id)
+ORDER BY e.id DESC
+LIMIT sqlc.arg(limit_val)::int OFFSET sqlc.arg(offset_val)::int;
+
+-- name: CountUserWalletEntries :one
+SELECT COUNT(*)
+FROM ledger_accounts a
+JOIN ledger_entries e ON e.account_id = a.id
+WHERE a.user_id = $1;
@@ -0,0 +1,16 @@
+-- name: CreateDeliveryZone :one
+INSERT INTO delivery_zones (
+  name, area, delivery_radius_m
+) VALUES (
+  sqlc.arg(name), ST_SetSRID(ST_GeomFromText(sqlc.arg(area_wkt)::text), 4326), sqlc.arg(delivery_radius_m)
+) RETURNING id;
+
+-- name: GetDeliveryZones :many
+SELECT
+  id,
+  name,
+  ST_AsGeoJSON(area)::text AS area,
+  delivery_radius_m,
+  created_at
+FROM delivery_zones
+ORDER BY created_at DESC, id ASC;